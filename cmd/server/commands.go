@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/yourname/leaguemaster/internal/services"
+)
+
+// runCommand handles CLI subcommands, e.g.
+//
+//	server create-admin -username alice -password 'one-time-pass' [-email alice@example.com] [-role super_admin]
+func runCommand(name string, args []string) {
+	switch name {
+	case "create-admin":
+		fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+		username := fs.String("username", "", "admin username")
+		password := fs.String("password", "", "one-time password, changed on first login")
+		email := fs.String("email", "", "email address for password resets")
+		role := fs.String("role", services.RoleSuperAdmin, "league-wide admin role")
+		fs.Parse(args)
+
+		if *username == "" || len(*password) < 6 {
+			fmt.Fprintln(os.Stderr, "create-admin: -username and a -password of at least 6 characters are required")
+			os.Exit(2)
+		}
+
+		var emailPtr *string
+		if *email != "" {
+			emailPtr = email
+		}
+		if _, err := services.NewAuthService().CreateAdmin(*username, *password, emailPtr, *role, nil); err != nil {
+			log.Fatal("Failed to create admin: ", err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (available: create-admin)\n", name)
+		os.Exit(2)
+	}
+}
+
+// bootstrapAdmin creates the first admin from ADMIN_BOOTSTRAP_USERNAME and
+// ADMIN_BOOTSTRAP_PASSWORD. It does nothing once any admin exists.
+func bootstrapAdmin() {
+	username := os.Getenv("ADMIN_BOOTSTRAP_USERNAME")
+	password := os.Getenv("ADMIN_BOOTSTRAP_PASSWORD")
+	if username == "" || password == "" {
+		return
+	}
+
+	created, err := services.NewAuthService().BootstrapAdmin(username, password)
+	if err != nil {
+		log.Fatal("Failed to bootstrap admin: ", err)
+	}
+	if created {
+		log.Printf("Bootstrapped admin '%s', the password must be changed on first login", username)
+	}
+}
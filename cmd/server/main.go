@@ -2,7 +2,10 @@ package main
 
 import (
 	"log"
+	"net"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -12,6 +15,9 @@ import (
 	models "github.com/yourname/leaguemaster/internal/models"
 	"github.com/yourname/leaguemaster/internal/services"
 	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/mailer"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -20,6 +26,10 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	if err := mailer.CheckConfig(); err != nil {
+		log.Fatal("Invalid mail configuration: ", err)
+	}
+
 	// Connect to Database
 	database.Connect()
 
@@ -27,37 +37,140 @@ func main() {
 	db := database.GetDB()
 	err := db.AutoMigrate(
 		&models.User{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.MFARecoveryCode{},
+		&models.UsedMFAToken{},
+		&models.LoginAttempt{},
+		&models.PlayerInvite{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.StreamTicket{},
+		&models.RoleAssignment{},
 		&models.Team{},
 		&models.Player{},
+		&models.TeamMember{},
+		&models.TeamMembership{},
+		&models.Season{},
+		&models.SquadSnapshot{},
+		&models.ArchivedStanding{},
+		&models.ArchivedPlayerStat{},
 		&models.Tournament{},
 		&models.Match{},
 		&models.MatchEvent{},
+		&models.MatchAppearance{},
+		&models.MatchAvailability{},
 		&models.Standing{},
+		&models.TransferWindow{},
+		&models.Transfer{},
 		&models.Notification{},
+		&models.NotificationTemplate{},
+		&models.NotificationBroadcast{},
+		&models.DeviceToken{},
+		&models.NotificationPreference{},
+		&models.PushDelivery{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
 		&models.Staff{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
+	// AutoMigrate doesn't widen enums on existing columns
+	if err := migrateMatchStatus(db); err != nil {
+		log.Fatal("Failed to migrate match status: ", err)
+	}
+
+	// Existing admins keep full access once roles are introduced
+	if err := services.NewPermissionService().BackfillSuperAdmins(); err != nil {
+		log.Fatal("Failed to backfill admin roles: ", err)
+	}
+
+	// CLI subcommands run against the migrated database and exit
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	bootstrapAdmin()
+
+	if err := services.NewTeamService().BackfillCaptains(); err != nil {
+		log.Fatal("Failed to backfill team captains: ", err)
+	}
+
+	if err := services.NewPlayerService().BackfillMemberships(); err != nil {
+		log.Fatal("Failed to backfill team memberships: ", err)
+	}
+
+	// Domain events become notifications...
+	services.RegisterNotificationSubscribers(services.Events())
+
+	// ...and wake the webhook dispatcher for the deliveries queued with them
+	services.RegisterWebhookSubscribers(services.Events())
+
+	// Background Jobs
+	go services.NewAvailabilityService().RunReminders(
+		utils.EnvDuration("AVAILABILITY_REMINDER_INTERVAL", 10*time.Minute),
+		utils.EnvDuration("AVAILABILITY_REMINDER_LEAD", 24*time.Hour),
+	)
+
+	go services.NewBroadcastService().RunScheduler(utils.EnvDuration("BROADCAST_INTERVAL", time.Minute))
+
+	go services.NewPushService().RunDispatcher(utils.EnvDuration("PUSH_DISPATCH_INTERVAL", 15*time.Second))
+
+	go services.NewNotificationService().RunExpiry(
+		utils.EnvDuration("NOTIFICATION_EXPIRY_INTERVAL", time.Hour),
+		services.NotificationRetention{
+			Read: utils.EnvDuration("NOTIFICATION_READ_RETENTION", 30*24*time.Hour),
+			All:  utils.EnvDuration("NOTIFICATION_RETENTION", 90*24*time.Hour),
+		},
+	)
+
+	go services.NewWebhookService().RunDispatcher(utils.EnvDuration("WEBHOOK_DISPATCH_INTERVAL", 15*time.Second))
+
+	go services.NewEmailNotificationService().RunEmails(
+		utils.EnvDuration("EMAIL_NOTIFICATION_INTERVAL", time.Minute),
+		utils.EnvInt("EMAIL_DIGEST_HOUR", 7),
+	)
 
 	// Initialize Echo
 	e := echo.New()
+	e.IPExtractor = ipExtractor()
 
 	// Middleware
-	e.Use(echoMiddleware.Logger())
+	e.Use(middleware.Logger())
 	e.Use(echoMiddleware.Recover())
 	e.Use(echoMiddleware.CORS())
 
+	// Uploaded images (player photos). Served as inert files, so nothing
+	// uploaded can run script on the API's origin.
+	uploads := e.Group("/uploads", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+			c.Response().Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+			return next(c)
+		}
+	})
+	uploads.Static("/", "uploads")
+
 	// Initialize Handlers
 	authHandler := handlers.NewAuthHandler()
-
-	// Seed Admin
-	services.NewAuthService().CreateAdmin("admin", "admin123")
-
 	publicHandler := handlers.NewPublicHandler()
 	captainHandler := handlers.NewCaptainHandler()
 	adminHandler := handlers.NewAdminHandler()
 	notificationHandler := handlers.NewNotificationHandler()
+	statsHandler := handlers.NewStatsHandler()
+	seasonHandler := handlers.NewSeasonHandler()
+	transferHandler := handlers.NewTransferHandler()
+	playerHandler := handlers.NewPlayerHandler()
+	availabilityHandler := handlers.NewAvailabilityHandler()
+	roleHandler := handlers.NewRoleHandler()
+	teamHandler := handlers.NewTeamHandler()
+	oidcHandler := handlers.NewOIDCHandler()
+	mfaHandler := handlers.NewMFAHandler()
+	liveHandler := handlers.NewLiveHandler()
+	deviceHandler := handlers.NewDeviceHandler()
+	webhookHandler := handlers.NewWebhookHandler()
 
 	// Routes
 	v1 := e.Group("/api/v1")
@@ -66,6 +179,28 @@ func main() {
 	auth := v1.Group("/auth")
 	auth.POST("/register", authHandler.Register)
 	auth.POST("/login", authHandler.Login)
+	auth.POST("/refresh", authHandler.Refresh)
+	auth.POST("/logout", authHandler.Logout)
+	auth.POST("/forgot-password", authHandler.ForgotPassword)
+	auth.POST("/reset-password", authHandler.ResetPassword)
+	auth.POST("/change-password", authHandler.ChangePassword, middleware.PasswordChangeAuth)
+	auth.POST("/claim", authHandler.ClaimInvite)
+
+	// Two-factor authentication (TOTP), mandatory for admins
+	auth.POST("/mfa/verify", mfaHandler.Verify, middleware.MFAPendingAuth)
+	auth.POST("/mfa/enroll", mfaHandler.Enroll, middleware.MFAEnrollAuth)
+	auth.POST("/mfa/confirm", mfaHandler.Confirm, middleware.MFAEnrollAuth)
+	auth.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes, middleware.AuthMiddleware)
+	auth.POST("/mfa/disable", mfaHandler.Disable, middleware.AuthMiddleware)
+
+	// Sign in with an OpenID Connect provider (authorization code + PKCE)
+	auth.GET("/oidc/providers", oidcHandler.GetProviders)
+	auth.GET("/oidc/:provider/login", oidcHandler.Login)
+	auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
+	auth.POST("/oidc/:provider/link", oidcHandler.Link, middleware.AuthMiddleware)
+	auth.POST("/oidc/:provider/link/callback", oidcHandler.LinkCallback, middleware.AuthMiddleware)
+	auth.GET("/identities", oidcHandler.GetIdentities, middleware.AuthMiddleware)
+	auth.DELETE("/identities/:id", oidcHandler.Unlink, middleware.AuthMiddleware)
 
 	// Public Routes (Open to All)
 	public := v1.Group("") // or just attach to v1 if no prefix desired, but spec says "Group A", usually implied under API root
@@ -76,72 +211,226 @@ func main() {
 	public.GET("/tournaments/:id/teams", publicHandler.GetTournamentTeams)
 	public.GET("/teams/:id", publicHandler.GetTeam)
 	public.GET("/players/:id", publicHandler.GetPlayer)
+	public.GET("/players/:id/teams", publicHandler.GetPlayerTeams)
+
+	// Live scores: matches in progress and event streams (Server-Sent Events)
+	public.GET("/matches/live", liveHandler.GetLiveMatches)
+	public.GET("/matches/:id/live", liveHandler.StreamMatch)
+	public.GET("/tournaments/:id/live", liveHandler.StreamTournament)
+
+	// Stats & Leaderboards (filter with ?tournament_id=&from=&to=)
+	public.GET("/stats/players", statsHandler.GetPlayerStats)
+	public.GET("/players/:id/stats", statsHandler.GetPlayerStat)
+	public.GET("/leaderboards/golden-boot", statsHandler.GetGoldenBoot)
+	public.GET("/leaderboards/assists", statsHandler.GetTopAssists)
+	public.GET("/leaderboards/fair-play", statsHandler.GetFairPlay)
+	public.GET("/teams/:id/stats", statsHandler.GetTeamStats)
+	public.GET("/teams/:id/head-to-head/:opponent_id", statsHandler.GetHeadToHead)
 
-	// Mobile Routes (Protected: Captain Role)
+	// Seasons & Archives
+	public.GET("/seasons", seasonHandler.GetSeasons)
+	public.GET("/seasons/:id", seasonHandler.GetSeason)
+	public.GET("/seasons/:id/standings", seasonHandler.GetArchivedStandings)
+	public.GET("/seasons/:id/stats", seasonHandler.GetArchivedStats)
+	public.GET("/seasons/:id/squads", seasonHandler.GetSquads)
+	public.GET("/players/:id/history", seasonHandler.GetPlayerHistory)
+
+	// Transfers
+	public.GET("/players/:id/transfers", transferHandler.GetPlayerTransfers)
+	public.GET("/transfer-windows", transferHandler.GetTransferWindows)
+
+	// Mobile Routes (Protected: any signed in user, narrowed by role below)
 	mobile := v1.Group("/mobile")
 	mobile.Use(middleware.AuthMiddleware)
-	mobile.Use(middleware.CaptainOnly)
 
-	// Note: Spec says "/my-team", so it's /api/v1/my-team
-	mobile.GET("/my-team", captainHandler.GetMyTeam)
-	mobile.PUT("/my-team", captainHandler.UpdateTeam)
-	mobile.POST("/my-team/players", captainHandler.AddPlayer)
-	mobile.DELETE("/my-team/players/:id", captainHandler.RemovePlayer)
-	mobile.POST("/matches/:id/events", captainHandler.AddMatchEvent)
+	// Any signed in user: the teams they run or play for
+	mobile.GET("/my-teams", teamHandler.GetMyTeams)
+
+	// Team Routes (captain, vice-captain or manager of a team; some need a higher role).
+	// They act on the team named by X-Team-ID (or the user's default team), and
+	// are also mounted under /teams/:team_id for an explicit team in the path.
+	viceCaptain := middleware.TeamRole(services.TeamRoleViceCaptain)
+	captainOnly := middleware.TeamRole(services.TeamRoleCaptain)
+	teamRoutes := func(captain *echo.Group) {
+		// Note: Spec says "/my-team", so it's /api/v1/my-team
+		captain.GET("/my-team", captainHandler.GetMyTeam)
+		captain.PUT("/my-team", captainHandler.UpdateTeam, viceCaptain)
+		captain.POST("/my-team/players", captainHandler.AddPlayer)
+		captain.DELETE("/my-team/players/:id", captainHandler.RemovePlayer)
+		captain.POST("/my-team/players/:id/invite", captainHandler.InvitePlayer)
+		captain.POST("/matches/:id/events", captainHandler.AddMatchEvent)
+		captain.POST("/matches/:id/lineup", captainHandler.SubmitLineup)
+		captain.POST("/matches/:id/result", captainHandler.SubmitResult)
+		captain.POST("/matches/:id/result/confirm", captainHandler.ConfirmResult)
+		captain.POST("/matches/:id/result/dispute", captainHandler.DisputeResult)
+		captain.GET("/free-agents", captainHandler.GetFreeAgents)
+
+		// Team Roles
+		captain.GET("/my-team/members", captainHandler.GetTeamMembers)
+		captain.POST("/my-team/members", captainHandler.SetTeamMember, captainOnly)
+		captain.DELETE("/my-team/members/:user_id", captainHandler.RemoveTeamMember, captainOnly)
+		captain.POST("/my-team/captain", captainHandler.TransferCaptaincy, captainOnly)
 
-	// Mobile Notification Routes
+		// Mobile Availability Routes
+		captain.GET("/availability", availabilityHandler.GetUpcomingAvailability)
+		captain.GET("/matches/:id/availability", availabilityHandler.GetMatchAvailability)
+		captain.PUT("/matches/:id/availability/:player_id", availabilityHandler.SetPlayerAvailability)
+
+		// Mobile Transfer Routes
+		captain.GET("/transfers", transferHandler.GetMyTransfers)
+		captain.POST("/transfers", transferHandler.RequestTransfer)
+		captain.POST("/transfers/:id/release", transferHandler.ReleasePlayer)
+		captain.POST("/transfers/:id/decline", transferHandler.DeclineTransfer)
+	}
+	captain := mobile.Group("", middleware.TeamRole(services.TeamRoleManager))
+	teamRoutes(captain)
+	teamRoutes(mobile.Group("/teams/:team_id", middleware.TeamRole(services.TeamRoleManager)))
+
+	// Mobile Notification Routes (inbox of any signed in user)
 	mobile.GET("/notifications", notificationHandler.GetMyNotifications)
+	mobile.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
 	mobile.POST("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+	mobile.POST("/notifications/read", notificationHandler.MarkNotificationsRead)
+	mobile.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+	mobile.POST("/notifications/archive", notificationHandler.ArchiveNotifications)
+	mobile.POST("/notifications/unarchive", notificationHandler.UnarchiveNotifications)
+	mobile.DELETE("/notifications/:id", notificationHandler.DeleteNotification)
+	mobile.POST("/notifications/delete", notificationHandler.DeleteNotifications)
+
+	// Push notifications: device tokens and per category preferences, for any signed in user
+	mobile.POST("/devices", deviceHandler.Register)
+	mobile.GET("/devices", deviceHandler.GetDevices)
+	mobile.DELETE("/devices/:id", deviceHandler.RemoveDevice)
+	mobile.GET("/notifications/preferences", deviceHandler.GetPreferences)
+	mobile.PUT("/notifications/preferences", deviceHandler.UpdatePreferences)
+
+	// Email notifications: off, one email each, or a daily digest
+	mobile.GET("/notifications/email", notificationHandler.GetEmailSettings)
+	mobile.PUT("/notifications/email", notificationHandler.UpdateEmailSettings)
+
+	// Live notifications (Server-Sent Events), for any signed in user. Browsers
+	// get a ticket first and pass it as ?ticket=, as EventSource can't set headers.
+	mobile.POST("/notifications/stream/ticket", notificationHandler.StreamTicket)
+	v1.GET("/mobile/notifications/stream", notificationHandler.Stream, middleware.StreamAuth)
+
+	// Player Routes (own profile, fixtures and stats)
+	player := mobile.Group("/me", middleware.PlayerOnly)
+	player.GET("", playerHandler.GetProfile)
+	player.PUT("", playerHandler.UpdateProfile)
+	player.POST("/photo", playerHandler.UploadPhoto)
+	player.GET("/fixtures", playerHandler.GetFixtures)
+	player.GET("/stats", playerHandler.GetStats)
+	player.PUT("/matches/:id/availability", availabilityHandler.SetMyAvailability)
 
 	// Admin Routes (Protected: Admin Role)
 	admin := v1.Group("/admin")
 	admin.Use(middleware.AuthMiddleware)
 	admin.Use(middleware.AdminOnly)
 
+	// Each admin route requires a permission from the admin's role assignments;
+	// tournament-scoped roles pass the tournament checks for their tournament only.
+	can := middleware.RequirePermission
+	canInTournament := func(perm string) echo.MiddlewareFunc {
+		return middleware.RequireTournamentPermission(perm, middleware.TournamentParam("id"))
+	}
+	canInMatch := func(perm string) echo.MiddlewareFunc {
+		return middleware.RequireTournamentPermission(perm, middleware.MatchTournament("id"))
+	}
+
 	// Admin User CRUD
-	admin.GET("/users", adminHandler.GetAllUsers)
-	admin.GET("/users/:id", adminHandler.GetUser)
-	admin.PUT("/users/:id", adminHandler.UpdateUser)
-	admin.DELETE("/users/:id", adminHandler.DeleteUser)
+	admin.GET("/users", adminHandler.GetAllUsers, can(services.PermUserView))
+	admin.GET("/users/:id", adminHandler.GetUser, can(services.PermUserView))
+	admin.PUT("/users/:id", adminHandler.UpdateUser, can(services.PermUserManage))
+	admin.DELETE("/users/:id", adminHandler.DeleteUser, can(services.PermUserManage))
+	admin.POST("/users/:id/mfa/reset", mfaHandler.ResetUser, can(services.PermUserManage))
+	admin.POST("/users/:id/unlock", adminHandler.UnlockUser, can(services.PermUserManage))
 
 	// Admin Team CRUD
-	admin.GET("/teams", adminHandler.GetAllTeams)
-	admin.POST("/teams", adminHandler.CreateTeam)
-	admin.PUT("/teams/:id", adminHandler.UpdateTeam)
-	admin.DELETE("/teams/:id", adminHandler.DeleteTeam)
+	admin.GET("/teams", adminHandler.GetAllTeams, can(services.PermTeamManage))
+	admin.POST("/teams", adminHandler.CreateTeam, can(services.PermTeamManage))
+	admin.PUT("/teams/:id", adminHandler.UpdateTeam, can(services.PermTeamManage))
+	admin.DELETE("/teams/:id", adminHandler.DeleteTeam, can(services.PermTeamManage))
+	admin.GET("/teams/:id/members", adminHandler.GetTeamMembers, can(services.PermTeamManage))
+	admin.POST("/teams/:id/members", adminHandler.SetTeamMember, can(services.PermTeamManage))
+	admin.DELETE("/teams/:id/members/:user_id", adminHandler.RemoveTeamMember, can(services.PermTeamManage))
+	admin.PUT("/teams/:id/captain", adminHandler.SetTeamCaptain, can(services.PermTeamManage))
 
 	// Admin Tournament Extensions
-	admin.POST("/tournaments", adminHandler.CreateTournament)
-	admin.PUT("/tournaments/:id", adminHandler.UpdateTournament)
-	admin.DELETE("/tournaments/:id", adminHandler.DeleteTournament)
-	admin.POST("/tournaments/:id/teams", adminHandler.AddTeamToTournament)
-	admin.DELETE("/tournaments/:id/teams/:team_id", adminHandler.RemoveTeamFromTournament)
-
-	admin.POST("/tournaments/:id/generate", adminHandler.GenerateBracket)
-	admin.POST("/matches/:id/resolve", adminHandler.ResolveMatch)
-	admin.GET("/dashboard/stats", adminHandler.GetDashboardStats)
-	admin.POST("/users/:id/ban", adminHandler.BanUser)
-	admin.POST("/players/:id/ban", adminHandler.BanPlayer)
+	admin.POST("/tournaments", adminHandler.CreateTournament, can(services.PermTournamentCreate))
+	admin.PUT("/tournaments/:id", adminHandler.UpdateTournament, canInTournament(services.PermTournamentEdit))
+	admin.DELETE("/tournaments/:id", adminHandler.DeleteTournament, can(services.PermTournamentDelete))
+	admin.POST("/tournaments/:id/teams", adminHandler.AddTeamToTournament, canInTournament(services.PermTournamentEdit))
+	admin.DELETE("/tournaments/:id/teams/:team_id", adminHandler.RemoveTeamFromTournament, canInTournament(services.PermTournamentEdit))
+
+	admin.POST("/tournaments/:id/generate", adminHandler.GenerateBracket, canInTournament(services.PermTournamentEdit))
+	admin.POST("/matches/:id/start", adminHandler.StartMatch, canInMatch(services.PermMatchResolve))
+	admin.POST("/matches/:id/resolve", adminHandler.ResolveMatch, canInMatch(services.PermMatchResolve))
+	admin.PUT("/matches/:id/schedule", adminHandler.ScheduleMatch, canInMatch(services.PermMatchSchedule))
+	admin.GET("/dashboard/stats", adminHandler.GetDashboardStats, can(services.PermDashboardView))
+	admin.POST("/users/:id/ban", adminHandler.BanUser, can(services.PermUserBan))
+	admin.POST("/players/:id/ban", adminHandler.BanPlayer, can(services.PermPlayerBan))
+
+	// Admin Seasons
+	admin.POST("/seasons", seasonHandler.CreateSeason, can(services.PermSeasonManage))
+	admin.PUT("/seasons/:id", seasonHandler.UpdateSeason, can(services.PermSeasonManage))
+	admin.DELETE("/seasons/:id", seasonHandler.DeleteSeason, can(services.PermSeasonManage))
+	admin.POST("/seasons/:id/snapshot", seasonHandler.SnapshotSquads, can(services.PermSeasonManage))
+	admin.POST("/seasons/:id/archive", seasonHandler.ArchiveSeason, can(services.PermSeasonManage))
 
 	// Admin Players Extensions
-	admin.GET("/players", adminHandler.GetAllPlayers)
-	admin.POST("/players", adminHandler.CreatePlayer)
-	admin.GET("/players/:id", adminHandler.GetPlayer)
-	admin.PUT("/players/:id", adminHandler.UpdatePlayer)
-	admin.DELETE("/players/:id", adminHandler.DeletePlayer)
+	admin.GET("/players", adminHandler.GetAllPlayers, can(services.PermPlayerManage))
+	admin.POST("/players", adminHandler.CreatePlayer, can(services.PermPlayerManage))
+	admin.GET("/players/:id", adminHandler.GetPlayer, can(services.PermPlayerManage))
+	admin.PUT("/players/:id", adminHandler.UpdatePlayer, can(services.PermPlayerManage))
+	admin.DELETE("/players/:id", adminHandler.DeletePlayer, can(services.PermPlayerManage))
+
+	// Admin Transfers
+	admin.GET("/transfers", transferHandler.GetAllTransfers, can(services.PermTransferReview))
+	admin.POST("/transfers/:id/approve", transferHandler.ApproveTransfer, can(services.PermTransferReview))
+	admin.POST("/transfers/:id/reject", transferHandler.RejectTransfer, can(services.PermTransferReview))
+	admin.POST("/transfer-windows", transferHandler.CreateTransferWindow, can(services.PermTransferWindows))
+	admin.PUT("/transfer-windows/:id", transferHandler.UpdateTransferWindow, can(services.PermTransferWindows))
+	admin.DELETE("/transfer-windows/:id", transferHandler.DeleteTransferWindow, can(services.PermTransferWindows))
 
 	// Admin Staff
-	admin.GET("/staff", adminHandler.GetAllStaff)
-	admin.POST("/staff", adminHandler.CreateStaff)
-	admin.GET("/staff/:id", adminHandler.GetStaff)
-	admin.PUT("/staff/:id", adminHandler.UpdateStaff)
-	admin.DELETE("/staff/:id", adminHandler.DeleteStaff)
+	admin.GET("/staff", adminHandler.GetAllStaff, can(services.PermStaffManage))
+	admin.POST("/staff", adminHandler.CreateStaff, can(services.PermStaffManage))
+	admin.GET("/staff/:id", adminHandler.GetStaff, can(services.PermStaffManage))
+	admin.PUT("/staff/:id", adminHandler.UpdateStaff, can(services.PermStaffManage))
+	admin.DELETE("/staff/:id", adminHandler.DeleteStaff, can(services.PermStaffManage))
+
+	// Admin Accounts & Roles
+	admin.POST("/admins", adminHandler.CreateAdmin, can(services.PermUserManage, services.PermRoleManage))
+	admin.GET("/roles", roleHandler.GetRoles)
+	admin.GET("/me/permissions", roleHandler.GetMyPermissions)
+	admin.GET("/role-assignments", roleHandler.GetAssignments, can(services.PermRoleManage))
+	admin.POST("/role-assignments", roleHandler.AssignRole, can(services.PermRoleManage))
+	admin.DELETE("/role-assignments/:id", roleHandler.RevokeRole, can(services.PermRoleManage))
 
 	// Admin Captains
-	admin.GET("/captains", adminHandler.GetAllCaptains)
+	admin.GET("/captains", adminHandler.GetAllCaptains, can(services.PermUserView))
 
 	// Admin Notifications
-	admin.POST("/notifications", adminHandler.SendNotification)
+	admin.POST("/notifications", adminHandler.SendNotification, can(services.PermNotificationSend))
+	admin.POST("/notifications/broadcasts", adminHandler.CreateBroadcast, can(services.PermNotificationSend))
+	admin.GET("/notifications/broadcasts", adminHandler.GetBroadcasts, can(services.PermNotificationSend))
+	admin.GET("/notifications/broadcasts/:id", adminHandler.GetBroadcast, can(services.PermNotificationSend))
+	admin.DELETE("/notifications/broadcasts/:id", adminHandler.CancelBroadcast, can(services.PermNotificationSend))
+	admin.POST("/notifications/broadcasts/:id/resume", adminHandler.ResumeBroadcast, can(services.PermNotificationSend))
+	admin.GET("/notification-templates", adminHandler.GetNotificationTemplates, can(services.PermNotificationTemplates))
+	admin.PUT("/notification-templates/:event", adminHandler.UpdateNotificationTemplate, can(services.PermNotificationTemplates))
+	admin.DELETE("/notification-templates/:event", adminHandler.ResetNotificationTemplate, can(services.PermNotificationTemplates))
+
+	// Outgoing Webhooks (signed JSON posts to integrations)
+	admin.GET("/webhooks/events", webhookHandler.GetEvents, can(services.PermWebhookManage))
+	admin.GET("/webhooks", webhookHandler.GetWebhooks, can(services.PermWebhookManage))
+	admin.POST("/webhooks", webhookHandler.CreateWebhook, can(services.PermWebhookManage))
+	admin.PUT("/webhooks/:id", webhookHandler.UpdateWebhook, can(services.PermWebhookManage))
+	admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook, can(services.PermWebhookManage))
+	admin.POST("/webhooks/:id/rotate-secret", webhookHandler.RotateSecret, can(services.PermWebhookManage))
+	admin.POST("/webhooks/:id/test", webhookHandler.SendTest, can(services.PermWebhookManage))
+	admin.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries, can(services.PermWebhookManage))
+	admin.POST("/webhooks/:id/deliveries/:delivery_id/retry", webhookHandler.RetryDelivery, can(services.PermWebhookManage))
 
 	// Start Server
 	// Start Server
@@ -154,3 +443,61 @@ func main() {
 	}
 	e.Logger.Fatal(e.Start(port))
 }
+
+// ipExtractor decides where the client IP (used to throttle logins) comes
+// from. Forwarding headers are only believed from the proxies listed in
+// TRUSTED_PROXIES (comma separated CIDRs or IPs); without any, the peer
+// address of the connection is used.
+func ipExtractor() echo.IPExtractor {
+	var ranges []string
+	for _, r := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, r := range ranges {
+		if !strings.Contains(r, "/") {
+			if ip := net.ParseIP(r); ip != nil && ip.To4() != nil {
+				r += "/32"
+			} else {
+				r += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(r)
+		if err != nil {
+			log.Fatalf("Invalid TRUSTED_PROXIES entry %q: %v", r, err)
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// migrateMatchStatus widens the match status enum of databases created before
+// the result workflow. Columns already holding every status are left alone.
+func migrateMatchStatus(db *gorm.DB) error {
+	columns, err := db.Migrator().ColumnTypes(&models.Match{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() != "status" {
+			continue
+		}
+		current, _ := column.ColumnType()
+		upToDate := true
+		for _, status := range []string{"scheduled", "live", "pending_verification", "disputed", "completed"} {
+			if !strings.Contains(current, "'"+status+"'") {
+				upToDate = false
+			}
+		}
+		if upToDate {
+			return nil
+		}
+	}
+	return db.Migrator().AlterColumn(&models.Match{}, "Status")
+}
@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
 	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
 )
 
 type AdminHandler struct{}
@@ -45,34 +51,81 @@ func (h *AdminHandler) UpdateUser(c echo.Context) error {
 	}
 
 	type UpdateUserRequest struct {
-		Role     string `json:"role"`
-		IsActive *bool  `json:"is_active"`
+		Role     string  `json:"role"`
+		IsActive *bool   `json:"is_active"`
+		Email    *string `json:"email"`
 	}
 	req := new(UpdateUserRequest)
 	if err := c.Bind(req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
+	if req.Role != "" && !slices.Contains([]string{"admin", "captain", "player"}, req.Role) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Role must be admin, captain or player"})
+	}
+	if err := services.CheckEmail(req.Email); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
 
-	if req.Role != "" {
+	if req.Role != "" && req.Role != user.Role {
+		// Admin roles only apply to admin accounts
+		if user.Role == "admin" {
+			if err := services.NewPermissionService().RevokeUser(user.ID); err != nil {
+				return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+			}
+		}
 		user.Role = req.Role
 	}
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
+	if req.Email != nil {
+		if *req.Email == "" {
+			user.Email = nil
+		} else {
+			user.Email = req.Email
+		}
+	}
 
-	database.GetDB().Save(&user)
+	if err := database.GetDB().Save(&user).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update user"})
+	}
+	services.Accounts().Invalidate(user.ID)
+	if !user.IsActive {
+		services.NewAuthService().RevokeAllSessions(user.ID)
+	}
 	return c.JSON(http.StatusOK, user)
 }
 
 // DELETE /admin/users/:id
 func (h *AdminHandler) DeleteUser(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if err := services.NewPermissionService().RevokeUser(uint(id)); err != nil {
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
+	if err := services.NewTeamService().RemoveUser(uint(id)); err != nil {
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
 	if err := database.GetDB().Delete(&models.User{}, id).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete user"})
 	}
+	services.Accounts().Invalidate(uint(id))
+	services.NewAuthService().RevokeAllSessions(uint(id))
 	return c.JSON(http.StatusOK, echo.Map{"message": "User deleted"})
 }
 
+// POST /admin/users/:id/unlock
+func (h *AdminHandler) UnlockUser(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var user models.User
+	if err := database.GetDB().First(&user, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+	}
+	if err := services.LoginAttempts().Unlock(user.Username, user.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to unlock user"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "User unlocked"})
+}
+
 // Team CRUD
 
 // GET /admin/teams
@@ -90,7 +143,7 @@ func (h *AdminHandler) CreateTeam(c echo.Context) error {
 	if err := c.Bind(&team); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
-	if err := database.GetDB().Create(&team).Error; err != nil {
+	if err := services.NewTeamService().CreateTeam(&team); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create team"})
 	}
 	return c.JSON(http.StatusCreated, team)
@@ -104,18 +157,79 @@ func (h *AdminHandler) UpdateTeam(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team not found"})
 	}
 
+	captainID := team.CaptainID
 	if err := c.Bind(&team); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
 
+	// Captaincy changes go through the team roles
+	newCaptainID := team.CaptainID
+	team.CaptainID = captainID
 	database.GetDB().Save(&team)
+	if newCaptainID != captainID && newCaptainID != 0 {
+		updated, err := services.NewTeamService().TransferCaptaincy(team.ID, newCaptainID)
+		if err != nil {
+			return teamRoleError(c, err)
+		}
+		team.CaptainID = updated.CaptainID
+	}
+	return c.JSON(http.StatusOK, team)
+}
+
+// GET /admin/teams/:id/members
+func (h *AdminHandler) GetTeamMembers(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	members, err := services.NewTeamService().Members(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch team members"})
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+// POST /admin/teams/:id/members
+func (h *AdminHandler) SetTeamMember(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(TeamMemberRequest)
+	if err := c.Bind(req); err != nil || req.UserID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id and role are required"})
+	}
+
+	member, err := services.NewTeamService().SetMember(uint(id), req.UserID, req.Role)
+	if err != nil {
+		return teamRoleError(c, err)
+	}
+	return c.JSON(http.StatusOK, member)
+}
+
+// DELETE /admin/teams/:id/members/:user_id
+func (h *AdminHandler) RemoveTeamMember(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := strconv.Atoi(c.Param("user_id"))
+	if err := services.NewTeamService().RemoveMember(uint(id), uint(userID)); err != nil {
+		return teamRoleError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Team role removed"})
+}
+
+// PUT /admin/teams/:id/captain
+func (h *AdminHandler) SetTeamCaptain(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(CaptaincyRequest)
+	if err := c.Bind(req); err != nil || req.UserID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id is required"})
+	}
+
+	team, err := services.NewTeamService().TransferCaptaincy(uint(id), req.UserID)
+	if err != nil {
+		return teamRoleError(c, err)
+	}
 	return c.JSON(http.StatusOK, team)
 }
 
 // DELETE /admin/teams/:id
 func (h *AdminHandler) DeleteTeam(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := database.GetDB().Delete(&models.Team{}, id).Error; err != nil {
+	if err := services.NewPlayerService().DeleteTeam(uint(id)); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete team"})
 	}
 	return c.JSON(http.StatusOK, echo.Map{"message": "Team deleted"})
@@ -133,33 +247,14 @@ func (h *AdminHandler) AddTeamToTournament(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
 
-	// Verify Tournament exists
-	var tournament models.Tournament
-	if err := database.GetDB().First(&tournament, tournamentID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "Tournament not found"})
-	}
-
-	// Verify Team exists
-	var team models.Team
-	if err := database.GetDB().First(&team, req.TeamID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team not found"})
+	_, err := services.NewTournamentService().RegisterTeam(uint(tournamentID), req.TeamID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Tournament or team not found"})
 	}
-
-	// Check if already registered
-	var count int64
-	database.GetDB().Model(&models.Standing{}).Where("tournament_id = ? AND team_id = ?", tournamentID, req.TeamID).Count(&count)
-	if count > 0 {
+	if errors.Is(err, services.ErrTeamAlreadyRegistered) {
 		return c.JSON(http.StatusConflict, echo.Map{"error": "Team already in tournament"})
 	}
-
-	// Create Standing entry to register team
-	standing := models.Standing{
-		TournamentID: uint(tournamentID),
-		TeamID:       req.TeamID,
-		Points:       0,
-	}
-
-	if err := database.GetDB().Create(&standing).Error; err != nil {
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to add team to tournament"})
 	}
 
@@ -248,16 +343,53 @@ func (h *AdminHandler) ResolveMatch(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request"})
 	}
 
-	var match models.Match
-	if err := database.GetDB().First(&match, id).Error; err != nil {
+	match, err := services.NewMatchService().SetResult(uint(id), req.Status, req.ScoreA, req.ScoreB)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	}
+	if errors.Is(err, services.ErrInvalidMatchState) || errors.Is(err, services.ErrInvalidScore) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update match"})
+	}
+
+	return c.JSON(http.StatusOK, match)
+}
+
+// POST /matches/:id/start
+func (h *AdminHandler) StartMatch(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	match, err := services.NewMatchService().StartMatch(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
 	}
+	if errors.Is(err, services.ErrMatchNotScheduled) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to start match"})
+	}
+	return c.JSON(http.StatusOK, match)
+}
+
+// PUT /matches/:id/schedule
+func (h *AdminHandler) ScheduleMatch(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
 
-	match.Status = req.Status
-	match.ScoreA = req.ScoreA
-	match.ScoreB = req.ScoreB
+	type ScheduleRequest struct {
+		ScheduledAt time.Time `json:"scheduled_at" form:"scheduled_at"`
+	}
+	req := new(ScheduleRequest)
+	if err := c.Bind(req); err != nil || req.ScheduledAt.IsZero() {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid schedule"})
+	}
 
-	if err := database.GetDB().Save(&match).Error; err != nil {
+	match, err := services.NewMatchService().ScheduleMatch(uint(id), req.ScheduledAt)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update match"})
 	}
 
@@ -283,14 +415,11 @@ func (h *AdminHandler) GetDashboardStats(c echo.Context) error {
 func (h *AdminHandler) BanUser(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	// Optional: Parse body to support unban? Or toggle?
-	// For simplicity, let's assume it sets Banned=true (or reads from body if complex)
-	// Let's implement toggle or specific instructions? "ban player or captain"
-	// I'll make it explicit: POST /ban sets to true. If they want unban, maybe specific route or body.
-
-	// Parse optional body for state
+	// Parse optional body for state; an empty body bans permanently
 	type BanRequest struct {
-		IsBanned *bool `json:"is_banned" form:"is_banned"`
+		IsBanned  *bool      `json:"is_banned" form:"is_banned"`
+		Reason    string     `json:"reason" form:"reason"`
+		ExpiresAt *time.Time `json:"expires_at" form:"expires_at"`
 	}
 	req := new(BanRequest)
 	c.Bind(req) // If fails, we default to banning
@@ -306,8 +435,42 @@ func (h *AdminHandler) BanUser(c echo.Context) error {
 		user.IsBanned = true // Default action
 	}
 
+	if user.IsBanned {
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "expires_at must be in the future"})
+		}
+		user.BanReason = req.Reason
+		user.BanExpiresAt = req.ExpiresAt
+	} else {
+		user.BanReason = ""
+		user.BanExpiresAt = nil
+	}
+
 	database.GetDB().Save(&user)
-	return c.JSON(http.StatusOK, echo.Map{"message": "User ban status updated", "is_banned": user.IsBanned})
+	services.Accounts().Invalidate(user.ID)
+
+	// Kick a banned user out of every session
+	if user.IsBanned {
+		if err := services.NewAuthService().RevokeAllSessions(user.ID); err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to revoke sessions"})
+		}
+
+		expires := "further notice"
+		if user.BanExpiresAt != nil {
+			expires = user.BanExpiresAt.Format("Mon 2 Jan 2006 15:04")
+		}
+		services.Events().Publish(services.DomainEvent{
+			Name:   services.EventBanApplied,
+			UserID: user.ID,
+			Data:   map[string]string{"reason": user.BanReason, "expires": expires},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":        "User ban status updated",
+		"is_banned":      user.IsBanned,
+		"ban_reason":     user.BanReason,
+		"ban_expires_at": user.BanExpiresAt,
+	})
 }
 
 // POST /players/:id/ban
@@ -320,36 +483,86 @@ func (h *AdminHandler) BanPlayer(c echo.Context) error {
 	req := new(BanRequest)
 	c.Bind(req)
 
-	var player models.Player
-	if err := database.GetDB().First(&player, id).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
-	}
-
+	banned := true
 	if req.IsBanned != nil {
-		player.IsBanned = *req.IsBanned
-	} else {
-		player.IsBanned = true
+		banned = *req.IsBanned
 	}
 
-	database.GetDB().Save(&player)
+	player, err := services.NewPlayerService().SetBanned(uint(id), banned)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update player"})
+	}
 	return c.JSON(http.StatusOK, echo.Map{"message": "Player ban status updated", "is_banned": player.IsBanned})
 }
 
 // Tournament CRUD Extensions
 
+// UpdateTournamentRequest holds a tournament's editable settings. The ID is
+// the one in the path, which the route's permission check was made for.
+type UpdateTournamentRequest struct {
+	Name     *string `json:"name" form:"name"`
+	Status   *string `json:"status" form:"status"`
+	MaxTeams *int    `json:"max_teams" form:"max_teams"`
+	SeasonID *uint   `json:"season_id" form:"season_id"`
+}
+
 // PUT /admin/tournaments/:id
+// Fields left out keep their value
 func (h *AdminHandler) UpdateTournament(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(UpdateTournamentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
 	var tournament models.Tournament
 	if err := database.GetDB().First(&tournament, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Tournament not found"})
 	}
 
-	if err := c.Bind(&tournament); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	var columns []string
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Name is required"})
+		}
+		tournament.Name = strings.TrimSpace(*req.Name)
+		columns = append(columns, "name")
+	}
+	if req.Status != nil {
+		if !slices.Contains([]string{"registration", "active", "completed"}, *req.Status) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Status must be registration, active or completed"})
+		}
+		tournament.Status = *req.Status
+		columns = append(columns, "status")
+	}
+	if req.MaxTeams != nil {
+		if *req.MaxTeams < 2 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "max_teams must be at least 2"})
+		}
+		tournament.MaxTeams = *req.MaxTeams
+		columns = append(columns, "max_teams")
+	}
+	if req.SeasonID != nil {
+		var season models.Season
+		if err := database.GetDB().First(&season, *req.SeasonID).Error; err != nil {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+		}
+		if season.Status == "archived" {
+			return c.JSON(http.StatusConflict, echo.Map{"error": "Season is archived"})
+		}
+		tournament.SeasonID = req.SeasonID
+		columns = append(columns, "season_id")
+	}
+	if len(columns) == 0 {
+		return c.JSON(http.StatusOK, tournament)
 	}
 
-	database.GetDB().Save(&tournament)
+	if err := database.GetDB().Model(&tournament).Select(columns).Updates(&tournament).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update tournament"})
+	}
 	return c.JSON(http.StatusOK, tournament)
 }
 
@@ -390,7 +603,7 @@ func (h *AdminHandler) CreatePlayer(c echo.Context) error {
 	if err := c.Bind(&player); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
-	if err := database.GetDB().Create(&player).Error; err != nil {
+	if err := services.NewPlayerService().CreatePlayer(&player); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create player"})
 	}
 	return c.JSON(http.StatusCreated, player)
@@ -406,19 +619,84 @@ func (h *AdminHandler) GetPlayer(c echo.Context) error {
 	return c.JSON(http.StatusOK, player)
 }
 
+// UpdatePlayerRequest holds a player's editable details. The team is left out:
+// moving between teams has to go through the transfer workflow.
+type UpdatePlayerRequest struct {
+	Name         *string `json:"name" form:"name"`
+	JerseyNumber *int    `json:"jersey_number" form:"jersey_number"`
+	Position     *string `json:"position" form:"position"`
+	Age          *int    `json:"age" form:"age"`
+	ImageURL     *string `json:"image_url" form:"image_url"`
+	Role         *string `json:"role" form:"role"`
+	Availability *string `json:"availability" form:"availability"`
+	GoalsScored  *int    `json:"goals_scored" form:"goals_scored"`
+	RedCards     *int    `json:"red_cards" form:"red_cards"`
+}
+
 // PUT /admin/players/:id
+// Fields left out keep their value
 func (h *AdminHandler) UpdatePlayer(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(UpdatePlayerRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
 	var player models.Player
 	if err := database.GetDB().First(&player, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
 	}
 
-	if err := c.Bind(&player); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	var columns []string
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Name is required"})
+		}
+		player.Name = strings.TrimSpace(*req.Name)
+		columns = append(columns, "name")
+	}
+	if req.JerseyNumber != nil {
+		player.JerseyNumber = *req.JerseyNumber
+		columns = append(columns, "jersey_number")
+	}
+	if req.Position != nil {
+		player.Position = *req.Position
+		columns = append(columns, "position")
+	}
+	if req.Age != nil {
+		player.Age = *req.Age
+		columns = append(columns, "age")
+	}
+	if req.ImageURL != nil {
+		player.ImageURL = *req.ImageURL
+		columns = append(columns, "image_url")
+	}
+	if req.Role != nil {
+		player.Role = *req.Role
+		columns = append(columns, "role")
+	}
+	if req.Availability != nil {
+		if !slices.Contains([]string{"available", "injured", "unavailable"}, *req.Availability) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Availability must be available, injured or unavailable"})
+		}
+		player.Availability = *req.Availability
+		columns = append(columns, "availability")
+	}
+	if req.GoalsScored != nil {
+		player.GoalsScored = *req.GoalsScored
+		columns = append(columns, "goals_scored")
+	}
+	if req.RedCards != nil {
+		player.RedCards = *req.RedCards
+		columns = append(columns, "red_cards")
+	}
+	if len(columns) == 0 {
+		return c.JSON(http.StatusOK, player)
 	}
 
-	database.GetDB().Save(&player)
+	if err := database.GetDB().Model(&player).Select(columns).Updates(&player).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update player"})
+	}
 	return c.JSON(http.StatusOK, player)
 }
 
@@ -487,6 +765,41 @@ func (h *AdminHandler) DeleteStaff(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"message": "Staff deleted"})
 }
 
+// POST /admin/admins
+func (h *AdminHandler) CreateAdmin(c echo.Context) error {
+	type CreateAdminRequest struct {
+		Username string `json:"username" form:"username"`
+		Password string `json:"password" form:"password"` // One-time, changed on first login
+		Email    string `json:"email" form:"email"`
+		Role     string `json:"role" form:"role"` // Optional league-wide admin role
+	}
+	req := new(CreateAdminRequest)
+	if err := c.Bind(req); err != nil || req.Username == "" || len(req.Password) < 6 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "username and a password of at least 6 characters are required"})
+	}
+
+	var email *string
+	if req.Email != "" {
+		email = &req.Email
+	}
+
+	if req.Role != "" {
+		if _, ok := services.RolePermissions[req.Role]; !ok {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": services.ErrUnknownRole.Error()})
+		}
+	}
+
+	grantedBy := getUserID(c)
+	admin, err := services.NewAuthService().CreateAdmin(req.Username, req.Password, email, req.Role, &grantedBy)
+	if errors.Is(err, services.ErrInvalidEmail) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, admin)
+}
+
 // Captains List
 func (h *AdminHandler) GetAllCaptains(c echo.Context) error {
 	var captains []models.User
@@ -509,15 +822,120 @@ func (h *AdminHandler) SendNotification(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
 	}
 
-	notification := models.Notification{
-		UserID:  req.UserID,
-		Message: req.Message,
-		IsRead:  false,
-	}
-
-	if err := database.GetDB().Create(&notification).Error; err != nil {
+	notification, err := services.NewNotificationService().Send(req.UserID, "general", req.Message)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to send notification"})
 	}
 
 	return c.JSON(http.StatusCreated, notification)
 }
+
+// broadcastError maps broadcast errors onto HTTP responses
+func broadcastError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidBroadcast):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Broadcast, tournament or team not found"})
+	case errors.Is(err, services.ErrBroadcastStarted), errors.Is(err, services.ErrBroadcastNotFailed):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to process broadcast"})
+	}
+}
+
+// POST /admin/notifications/broadcasts
+func (h *AdminHandler) CreateBroadcast(c echo.Context) error {
+	req := new(services.BroadcastRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	broadcast, err := services.NewBroadcastService().Create(*req, getUserID(c))
+	if err != nil {
+		return broadcastError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, broadcast)
+}
+
+// GET /admin/notifications/broadcasts
+func (h *AdminHandler) GetBroadcasts(c echo.Context) error {
+	broadcasts, err := services.NewBroadcastService().List()
+	if err != nil {
+		return broadcastError(c, err)
+	}
+	return c.JSON(http.StatusOK, broadcasts)
+}
+
+// GET /admin/notifications/broadcasts/:id
+func (h *AdminHandler) GetBroadcast(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	broadcast, err := services.NewBroadcastService().Get(uint(id))
+	if err != nil {
+		return broadcastError(c, err)
+	}
+	return c.JSON(http.StatusOK, broadcast)
+}
+
+// DELETE /admin/notifications/broadcasts/:id
+func (h *AdminHandler) CancelBroadcast(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := services.NewBroadcastService().Cancel(uint(id)); err != nil {
+		return broadcastError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Broadcast cancelled"})
+}
+
+// POST /admin/notifications/broadcasts/:id/resume
+// Sends a failed broadcast to the recipients it didn't reach
+func (h *AdminHandler) ResumeBroadcast(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	broadcast, err := services.NewBroadcastService().Resume(uint(id))
+	if err != nil {
+		return broadcastError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, broadcast)
+}
+
+// GET /admin/notification-templates
+func (h *AdminHandler) GetNotificationTemplates(c echo.Context) error {
+	templates, err := services.NewNotificationTemplateService().Templates()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch templates"})
+	}
+	return c.JSON(http.StatusOK, templates)
+}
+
+// PUT /admin/notification-templates/:event
+func (h *AdminHandler) UpdateNotificationTemplate(c echo.Context) error {
+	type TemplateRequest struct {
+		Body string `json:"body" form:"body"`
+	}
+	req := new(TemplateRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	template, err := services.NewNotificationTemplateService().Update(c.Param("event"), req.Body, getUserID(c))
+	switch {
+	case errors.Is(err, services.ErrUnknownEvent):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTemplate):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to save template"})
+	}
+	return c.JSON(http.StatusOK, template)
+}
+
+// DELETE /admin/notification-templates/:event
+func (h *AdminHandler) ResetNotificationTemplate(c echo.Context) error {
+	err := services.NewNotificationTemplateService().Reset(c.Param("event"))
+	if errors.Is(err, services.ErrUnknownEvent) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to reset template"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Template reset to default"})
+}
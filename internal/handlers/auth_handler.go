@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
 	"github.com/yourname/leaguemaster/internal/services"
 )
 
@@ -21,6 +25,7 @@ type RegisterRequest struct {
 	Username string `json:"username" form:"username" validate:"required"`
 	Password string `json:"password" form:"password" validate:"required,min=6"`
 	TeamName string `json:"team_name" form:"team_name" validate:"required"`
+	Email    string `json:"email" form:"email"` // Optional, used for password resets
 }
 
 func (h *AuthHandler) Register(c echo.Context) error {
@@ -29,7 +34,15 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	user, err := h.service.RegisterCaptain(req.Username, req.Password, req.TeamName)
+	var email *string
+	if req.Email != "" {
+		email = &req.Email
+	}
+
+	user, err := h.service.RegisterCaptain(req.Username, req.Password, req.TeamName, email)
+	if errors.Is(err, services.ErrInvalidEmail) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -37,6 +50,30 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	return c.JSON(http.StatusCreated, user)
 }
 
+type ClaimInviteRequest struct {
+	Code     string `json:"code" form:"code" validate:"required"`
+	Username string `json:"username" form:"username" validate:"required"`
+	Password string `json:"password" form:"password" validate:"required,min=6"`
+}
+
+// POST /auth/claim
+func (h *AuthHandler) ClaimInvite(c echo.Context) error {
+	req := new(ClaimInviteRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.Code == "" || req.Username == "" || len(req.Password) < 6 {
+		return echo.NewHTTPError(http.StatusBadRequest, "code, username and a password of at least 6 characters are required")
+	}
+
+	user, err := h.service.ClaimPlayerInvite(req.Code, req.Username, req.Password)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, user)
+}
+
 type LoginRequest struct {
 	Username string `json:"username" form:"username" validate:"required"`
 	Password string `json:"password" form:"password" validate:"required"`
@@ -48,18 +85,183 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	token, user, err := h.service.Login(req.Username, req.Password)
+	result, err := h.service.Login(req.Username, req.Password, c.RealIP())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		return accountError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, echo.Map{
-		"token": token,
+	return c.JSON(http.StatusOK, loginResponse(result))
+}
+
+// loginResponse is a session, or the MFA challenge when a second factor is needed
+func loginResponse(result *services.LoginResult) echo.Map {
+	if result.MFA != nil {
+		return echo.Map{
+			"mfa_required":        true,
+			"mfa_token":           result.MFA.Token,
+			"expires_in":          result.MFA.ExpiresIn,
+			"enrollment_required": result.MFA.EnrollmentRequired,
+		}
+	}
+	return sessionResponse(result.Tokens, result.User)
+}
+
+// accountError maps login/account errors onto responses with a stable "code"
+// the app can switch on. Banned users get the reason and expiry.
+func accountError(c echo.Context, err error) error {
+	var ban *services.BanError
+	var throttle *services.ThrottleError
+	switch {
+	case errors.As(err, &ban):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error":          err.Error(),
+			"code":           "account_banned",
+			"ban_reason":     ban.Reason,
+			"ban_expires_at": ban.ExpiresAt,
+		})
+	case errors.Is(err, services.ErrAccountInactive):
+		return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "account_inactive"})
+	case errors.As(err, &throttle):
+		code := "too_many_attempts"
+		if throttle.Locked {
+			code = "account_locked"
+		}
+		retryAfter := int(math.Ceil(throttle.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return c.JSON(http.StatusTooManyRequests, echo.Map{"error": err.Error(), "code": code, "retry_after": retryAfter})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": err.Error(), "code": "invalid_credentials"})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Authentication failed"})
+	}
+}
+
+// sessionResponse is the body returned whenever a new token pair is issued.
+// "token" is kept alongside "access_token" for older app versions.
+func sessionResponse(tokens *services.TokenPair, user *models.User) echo.Map {
+	return echo.Map{
+		"token":                tokens.AccessToken,
+		"access_token":         tokens.AccessToken,
+		"refresh_token":        tokens.RefreshToken,
+		"token_type":           tokens.TokenType,
+		"expires_in":           tokens.ExpiresIn,
+		"must_change_password": user.MustChangePassword,
+		"totp_enabled":         user.TOTPEnabled,
 		"user": echo.Map{
-			"id":       user.ID,
-			"username": user.Username,
-			"role":     user.Role,
-			"team_id":  user.TeamID,
+			"id":        user.ID,
+			"username":  user.Username,
+			"role":      user.Role,
+			"team_id":   user.TeamID,
+			"player_id": user.PlayerID,
 		},
-	})
+	}
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token" validate:"required"`
+}
+
+// POST /auth/refresh
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	req := new(RefreshRequest)
+	if err := c.Bind(req); err != nil || req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "refresh_token is required")
+	}
+
+	tokens, user, err := h.service.Refresh(req.RefreshToken)
+	if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, sessionResponse(tokens, user))
+}
+
+// POST /auth/logout
+func (h *AuthHandler) Logout(c echo.Context) error {
+	req := new(RefreshRequest)
+	if err := c.Bind(req); err != nil || req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "refresh_token is required")
+	}
+
+	// Unknown tokens are treated as already logged out
+	if err := h.service.Logout(req.RefreshToken); err != nil && !errors.Is(err, services.ErrInvalidRefreshToken) {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "Logged out"})
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" validate:"required,min=6"`
+}
+
+// POST /auth/change-password
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	req := new(ChangePasswordRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if len(req.NewPassword) < 6 {
+		return echo.NewHTTPError(http.StatusBadRequest, "new_password must be at least 6 characters")
+	}
+
+	tokens, user, err := h.service.ChangePassword(getUserID(c), req.CurrentPassword, req.NewPassword)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "current password is incorrect")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, sessionResponse(tokens, user))
+}
+
+type ForgotPasswordRequest struct {
+	// Username or email address
+	Identifier string `json:"identifier" form:"identifier" validate:"required"`
+}
+
+// POST /auth/forgot-password
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	req := new(ForgotPasswordRequest)
+	if err := c.Bind(req); err != nil || req.Identifier == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "identifier is required")
+	}
+
+	if err := h.service.RequestPasswordReset(req.Identifier); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to send reset email")
+	}
+
+	// Same answer whether or not the account exists
+	return c.JSON(http.StatusOK, echo.Map{"message": "If the account exists, a reset link has been sent"})
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" form:"token" validate:"required"`
+	NewPassword string `json:"new_password" form:"new_password" validate:"required,min=6"`
+}
+
+// POST /auth/reset-password
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	req := new(ResetPasswordRequest)
+	if err := c.Bind(req); err != nil || req.Token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token is required")
+	}
+	if len(req.NewPassword) < 6 {
+		return echo.NewHTTPError(http.StatusBadRequest, "new_password must be at least 6 characters")
+	}
+
+	err := h.service.ResetPassword(req.Token, req.NewPassword)
+	if errors.Is(err, services.ErrInvalidResetToken) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "Password has been reset, please log in"})
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+type AvailabilityHandler struct {
+	service *services.AvailabilityService
+}
+
+func NewAvailabilityHandler() *AvailabilityHandler {
+	return &AvailabilityHandler{
+		service: services.NewAvailabilityService(),
+	}
+}
+
+type AvailabilityRequest struct {
+	Status string `json:"status" form:"status"` // available, unavailable or maybe
+	Note   string `json:"note" form:"note"`
+}
+
+func (h *AvailabilityHandler) setAvailability(c echo.Context, playerID uint) error {
+	matchID, _ := strconv.Atoi(c.Param("id"))
+
+	req := new(AvailabilityRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request"})
+	}
+
+	availability, err := h.service.SetAvailability(uint(matchID), playerID, req.Status, req.Note, getUserID(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, availability)
+}
+
+// Player
+
+// PUT /me/matches/:id/availability
+func (h *AvailabilityHandler) SetMyAvailability(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+	return h.setAvailability(c, player.ID)
+}
+
+// Captain
+
+// PUT /matches/:id/availability/:player_id
+func (h *AvailabilityHandler) SetPlayerAvailability(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	playerID, _ := strconv.Atoi(c.Param("player_id"))
+	var player models.Player
+	if err := database.GetDB().Where("id = ? AND team_id = ?", playerID, teamID).First(&player).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found in your team"})
+	}
+
+	return h.setAvailability(c, uint(playerID))
+}
+
+// GET /matches/:id/availability
+func (h *AvailabilityHandler) GetMatchAvailability(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	matchID, _ := strconv.Atoi(c.Param("id"))
+	summary, err := h.service.Summary(uint(matchID), teamID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, summary)
+}
+
+// GET /availability
+func (h *AvailabilityHandler) GetUpcomingAvailability(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	summaries, err := h.service.UpcomingSummaries(teamID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch availability"})
+	}
+	return c.JSON(http.StatusOK, summaries)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/yourname/leaguemaster/internal/models"
 	"github.com/yourname/leaguemaster/internal/services"
 	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
 )
 
 type CaptainHandler struct{}
@@ -22,20 +24,21 @@ func getUserID(c echo.Context) uint {
 	return user.UserID
 }
 
+// Helper to get the team the user manages, as resolved by middleware.TeamRole
+func getCaptainTeamID(c echo.Context) (uint, bool) {
+	teamID, ok := c.Get("team_id").(uint)
+	return teamID, ok
+}
+
 // GET /my-team
 func (h *CaptainHandler) GetMyTeam(c echo.Context) error {
-	userID := getUserID(c)
-	var user models.User
-	if err := database.GetDB().First(&user, userID).Error; err != nil {
-		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "User not found"})
-	}
-
-	if user.TeamID == nil {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned to this captain"})
 	}
 
 	var team models.Team
-	if err := database.GetDB().Preload("Players").First(&team, *user.TeamID).Error; err != nil {
+	if err := database.GetDB().Preload("Players").First(&team, teamID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team not found"})
 	}
 
@@ -49,10 +52,8 @@ type UpdateTeamRequest struct {
 }
 
 func (h *CaptainHandler) UpdateTeam(c echo.Context) error {
-	userID := getUserID(c)
-	var user models.User
-	database.GetDB().First(&user, userID)
-	if user.TeamID == nil {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
 	}
 
@@ -62,7 +63,7 @@ func (h *CaptainHandler) UpdateTeam(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.GetDB().First(&team, *user.TeamID).Error; err != nil {
+	if err := database.GetDB().First(&team, teamID).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Database error"})
 	}
 
@@ -79,10 +80,8 @@ func (h *CaptainHandler) UpdateTeam(c echo.Context) error {
 
 // POST /my-team/players
 func (h *CaptainHandler) AddPlayer(c echo.Context) error {
-	userID := getUserID(c)
-	var user models.User
-	database.GetDB().First(&user, userID)
-	if user.TeamID == nil {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
 	}
 
@@ -91,9 +90,9 @@ func (h *CaptainHandler) AddPlayer(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid player data"})
 	}
 
-	player.TeamID = *user.TeamID // Force assignment to captain's team
+	player.TeamID = &teamID // Force assignment to captain's team
 
-	if err := database.GetDB().Create(&player).Error; err != nil {
+	if err := services.NewPlayerService().CreatePlayer(&player); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to add player"})
 	}
 
@@ -102,10 +101,8 @@ func (h *CaptainHandler) AddPlayer(c echo.Context) error {
 
 // DELETE /my-team/players/:id
 func (h *CaptainHandler) RemovePlayer(c echo.Context) error {
-	userID := getUserID(c)
-	var user models.User
-	database.GetDB().First(&user, userID)
-	if user.TeamID == nil {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
 	}
 
@@ -113,63 +110,275 @@ func (h *CaptainHandler) RemovePlayer(c echo.Context) error {
 
 	// Ensure player belongs to captain's team
 	var player models.Player
-	if err := database.GetDB().Where("id = ? AND team_id = ?", playerID, *user.TeamID).First(&player).Error; err != nil {
+	if err := database.GetDB().Where("id = ? AND team_id = ?", playerID, teamID).First(&player).Error; err != nil {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found in your team"})
 	}
 
-	database.GetDB().Delete(&player)
-	return c.JSON(http.StatusOK, echo.Map{"message": "Player removed"})
+	// Players outlive their teams: removing one makes them a free agent
+	if err := services.NewPlayerService().ReleasePlayer(player.ID, teamID); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to remove player"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Player released to free agency"})
+}
+
+// POST /my-team/players/:id/invite
+func (h *CaptainHandler) InvitePlayer(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	playerID, _ := strconv.Atoi(c.Param("id"))
+	invite, err := services.NewPlayerService().CreateInvite(uint(playerID), teamID, getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, invite)
 }
 
-// POST /matches/:id/events
 // POST /matches/:id/events
 func (h *CaptainHandler) AddMatchEvent(c echo.Context) error {
 	matchID, _ := strconv.Atoi(c.Param("id"))
-	userID := getUserID(c)
 
 	// Get Captain's Team
-	var user models.User
-	if err := database.GetDB().First(&user, userID).Error; err != nil {
-		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "User not found"})
+	captainTeamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "No team assigned"})
+	}
+
+	var event models.MatchEvent
+	if err := c.Bind(&event); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid event data"})
 	}
-	if user.TeamID == nil {
+
+	// The service checks the match, the team and the player
+	created, err := services.NewMatchService().AddMatchEvent(uint(matchID), captainTeamID, event.PlayerID, event.AssistPlayerID, event.EventType, event.Minute)
+	if err != nil {
+		return matchEventError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// matchEventError maps match event and lineup errors onto HTTP responses
+func matchEventError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match or player not found"})
+	case errors.Is(err, services.ErrTeamNotInMatch):
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "Your team is not participating in this match"})
+	case errors.Is(err, services.ErrMatchCompleted), errors.Is(err, services.ErrMatchNotStarted),
+		errors.Is(err, services.ErrResultReported):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrPlayerNotInMatch), errors.Is(err, services.ErrInvalidAssist),
+		errors.Is(err, services.ErrLineupOutsideTeam):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to record match data"})
+	}
+}
+
+// POST /matches/:id/lineup
+type LineupRequest struct {
+	PlayerIDs []uint `json:"player_ids" form:"player_ids"`
+}
+
+func (h *CaptainHandler) SubmitLineup(c echo.Context) error {
+	matchID, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
 		return c.JSON(http.StatusForbidden, echo.Map{"error": "No team assigned"})
 	}
-	captainTeamID := *user.TeamID
 
-	// Verify Match exists and Captain's Team is playing
-	var match models.Match
-	if err := database.GetDB().First(&match, matchID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	req := new(LineupRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid lineup data"})
+	}
+
+	if err := services.NewMatchService().RecordLineup(uint(matchID), teamID, req.PlayerIDs); err != nil {
+		return matchEventError(c, err)
 	}
 
-	if (match.TeamAID == nil || *match.TeamAID != captainTeamID) && (match.TeamBID == nil || *match.TeamBID != captainTeamID) {
+	return c.JSON(http.StatusOK, echo.Map{"message": "Lineup saved"})
+}
+
+// Match Results
+
+// matchResultError maps result submission errors onto HTTP responses
+func matchResultError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Match not found"})
+	case errors.Is(err, services.ErrTeamNotInMatch):
 		return c.JSON(http.StatusForbidden, echo.Map{"error": "Your team is not participating in this match"})
+	case errors.Is(err, services.ErrResultClosed), errors.Is(err, services.ErrNoPendingResult), errors.Is(err, services.ErrOwnResult),
+		errors.Is(err, services.ErrResultPending):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidScore):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to record the result"})
 	}
+}
 
-	var event models.MatchEvent
-	if err := c.Bind(&event); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid event data"})
+type ResultRequest struct {
+	ScoreA int `json:"score_a" form:"score_a"`
+	ScoreB int `json:"score_b" form:"score_b"`
+}
+
+// POST /matches/:id/result
+func (h *CaptainHandler) SubmitResult(c echo.Context) error {
+	matchID, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "No team assigned"})
 	}
-	event.MatchID = uint(matchID)
 
-	// Validate Player belongs to one of the teams in the match
-	var player models.Player
-	if err := database.GetDB().First(&player, event.PlayerID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
+	req := new(ResultRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid result"})
+	}
+
+	match, err := services.NewMatchService().SubmitResult(uint(matchID), teamID, req.ScoreA, req.ScoreB)
+	if err != nil {
+		return matchResultError(c, err)
+	}
+	return c.JSON(http.StatusOK, match)
+}
+
+// POST /matches/:id/result/confirm
+func (h *CaptainHandler) ConfirmResult(c echo.Context) error {
+	matchID, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "No team assigned"})
+	}
+
+	match, err := services.NewMatchService().ConfirmResult(uint(matchID), teamID)
+	if err != nil {
+		return matchResultError(c, err)
+	}
+	return c.JSON(http.StatusOK, match)
+}
+
+// POST /matches/:id/result/dispute
+func (h *CaptainHandler) DisputeResult(c echo.Context) error {
+	matchID, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "No team assigned"})
 	}
 
-	// Check if player's team is in the match
-	isTeamA := match.TeamAID != nil && player.TeamID == *match.TeamAID
-	isTeamB := match.TeamBID != nil && player.TeamID == *match.TeamBID
+	type DisputeRequest struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	req := new(DisputeRequest)
+	if err := c.Bind(req); err != nil || req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "reason is required"})
+	}
 
-	if !isTeamA && !isTeamB {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Player does not belong to any team in this match"})
+	match, err := services.NewMatchService().DisputeResult(uint(matchID), teamID, req.Reason)
+	if err != nil {
+		return matchResultError(c, err)
 	}
+	return c.JSON(http.StatusOK, match)
+}
 
-	if err := database.GetDB().Create(&event).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create event"})
+// GET /free-agents
+func (h *CaptainHandler) GetFreeAgents(c echo.Context) error {
+	players, err := services.NewPlayerService().FreeAgents(c.QueryParam("position"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch free agents"})
 	}
+	return c.JSON(http.StatusOK, players)
+}
+
+// Team Roles
+
+// teamRoleError maps team role errors onto HTTP responses, shared with the admin endpoints
+func teamRoleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team or user not found"})
+	case errors.Is(err, services.ErrCaptainNotRemoved), errors.Is(err, services.ErrUserIsCaptain):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+}
+
+// GET /my-team/members
+func (h *CaptainHandler) GetTeamMembers(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	members, err := services.NewTeamService().Members(teamID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch team members"})
+	}
+	return c.JSON(http.StatusOK, members)
+}
 
-	return c.JSON(http.StatusCreated, event)
+type TeamMemberRequest struct {
+	UserID uint   `json:"user_id" form:"user_id"`
+	Role   string `json:"role" form:"role"` // vice_captain or manager
+}
+
+// POST /my-team/members
+func (h *CaptainHandler) SetTeamMember(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	req := new(TeamMemberRequest)
+	if err := c.Bind(req); err != nil || req.UserID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id and role are required"})
+	}
+
+	member, err := services.NewTeamService().SetMember(teamID, req.UserID, req.Role)
+	if err != nil {
+		return teamRoleError(c, err)
+	}
+	return c.JSON(http.StatusOK, member)
+}
+
+// DELETE /my-team/members/:user_id
+func (h *CaptainHandler) RemoveTeamMember(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	userID, _ := strconv.Atoi(c.Param("user_id"))
+	if err := services.NewTeamService().RemoveMember(teamID, uint(userID)); err != nil {
+		return teamRoleError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Team role removed"})
+}
+
+type CaptaincyRequest struct {
+	UserID uint `json:"user_id" form:"user_id"`
+}
+
+// POST /my-team/captain
+func (h *CaptainHandler) TransferCaptaincy(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	req := new(CaptaincyRequest)
+	if err := c.Bind(req); err != nil || req.UserID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id is required"})
+	}
+
+	team, err := services.NewTeamService().TransferCaptaincy(teamID, req.UserID)
+	if err != nil {
+		return teamRoleError(c, err)
+	}
+	return c.JSON(http.StatusOK, team)
 }
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+type DeviceHandler struct {
+	service *services.DeviceService
+}
+
+func NewDeviceHandler() *DeviceHandler {
+	return &DeviceHandler{
+		service: services.NewDeviceService(),
+	}
+}
+
+func deviceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidDevice), errors.Is(err, services.ErrUnknownCategory):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Device not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update devices"})
+	}
+}
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" form:"token"`
+	Platform string `json:"platform" form:"platform"` // ios, android or web
+}
+
+// POST /mobile/devices
+// Apps call this on every start, which keeps the token from being pruned
+func (h *DeviceHandler) Register(c echo.Context) error {
+	req := new(RegisterDeviceRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	device, err := h.service.Register(getUserID(c), req.Token, req.Platform)
+	if err != nil {
+		return deviceError(c, err)
+	}
+	return c.JSON(http.StatusOK, device)
+}
+
+// GET /mobile/devices
+func (h *DeviceHandler) GetDevices(c echo.Context) error {
+	devices, err := h.service.Devices(getUserID(c))
+	if err != nil {
+		return deviceError(c, err)
+	}
+	return c.JSON(http.StatusOK, devices)
+}
+
+// DELETE /mobile/devices/:id
+func (h *DeviceHandler) RemoveDevice(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Remove(getUserID(c), uint(id)); err != nil {
+		return deviceError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Device removed"})
+}
+
+// GET /mobile/notifications/preferences
+func (h *DeviceHandler) GetPreferences(c echo.Context) error {
+	prefs, err := h.service.Preferences(getUserID(c))
+	if err != nil {
+		return deviceError(c, err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// PUT /mobile/notifications/preferences
+// Body: {"push": {"match.scheduled": true, "announcement": false}}. Categories
+// left out keep their setting.
+func (h *DeviceHandler) UpdatePreferences(c echo.Context) error {
+	var req struct {
+		Push map[string]bool `json:"push"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	prefs, err := h.service.SetPreferences(getUserID(c), req.Push)
+	if err != nil {
+		return deviceError(c, err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/pubsub"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type LiveHandler struct {
+	service *services.LiveService
+}
+
+func NewLiveHandler() *LiveHandler {
+	return &LiveHandler{
+		service: services.NewLiveService(),
+	}
+}
+
+// liveStreams counts the open spectator streams, capped by LIVE_FEED_MAX_STREAMS
+var liveStreams int64
+
+// GET /matches/live?tournament_id=
+func (h *LiveHandler) GetLiveMatches(c echo.Context) error {
+	tournamentID, _ := strconv.Atoi(c.QueryParam("tournament_id"))
+	matches, err := h.service.LiveMatches(uint(tournamentID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch live matches"})
+	}
+	return c.JSON(http.StatusOK, matches)
+}
+
+// GET /matches/:id/live
+func (h *LiveHandler) StreamMatch(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	sub := h.service.SubscribeMatch(uint(id))
+	defer sub.Close()
+
+	return h.stream(c, sub, func() (interface{}, error) {
+		return h.service.MatchSnapshot(uint(id))
+	})
+}
+
+// GET /tournaments/:id/live
+func (h *LiveHandler) StreamTournament(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	sub := h.service.SubscribeTournament(uint(id))
+	defer sub.Close()
+
+	return h.stream(c, sub, func() (interface{}, error) {
+		return h.service.TournamentSnapshot(uint(id))
+	})
+}
+
+// stream sends a "snapshot" event on connect, then each live event (goal,
+// card_yellow, card_red, status) as it happens. The snapshot is sent again
+// every so often and whenever the client fell behind, so it never drifts for
+// long; clients can dedupe match events by their ID. The subscription is
+// opened before the snapshot is read so nothing falls in between.
+func (h *LiveHandler) stream(c echo.Context, sub *pubsub.Subscription, snapshot func() (interface{}, error)) error {
+	if atomic.AddInt64(&liveStreams, 1) > int64(utils.EnvInt("LIVE_FEED_MAX_STREAMS", 1000)) {
+		atomic.AddInt64(&liveStreams, -1)
+		return c.JSON(http.StatusServiceUnavailable, echo.Map{"error": "Too many live viewers, try again later"})
+	}
+	defer atomic.AddInt64(&liveStreams, -1)
+
+	state, err := snapshot()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to open live feed"})
+	}
+
+	openEventStream(c, utils.EnvDuration("LIVE_FEED_RETRY", 3*time.Second))
+	res := c.Response()
+	if err := writeEvent(res, "", "snapshot", state); err != nil {
+		return nil
+	}
+
+	sendSnapshot := func() error {
+		state, err := snapshot()
+		if err != nil {
+			log.Printf("Live feed snapshot failed: %v", err)
+			return err
+		}
+		return writeEvent(res, "", "snapshot", state)
+	}
+
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	refresh := time.NewTicker(utils.EnvDuration("LIVE_FEED_SNAPSHOT_INTERVAL", time.Minute))
+	defer refresh.Stop()
+
+	var dropped int64
+	ctx := c.Request().Context()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				err = sendSnapshot()
+				break
+			}
+			event := msg.(services.LiveEvent)
+			err = writeEvent(res, "", event.Type, event)
+		case <-ping.C:
+			err = writePing(res)
+		case <-refresh.C:
+			err = sendSnapshot()
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
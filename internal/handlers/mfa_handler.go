@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+type MFAHandler struct {
+	service *services.MFAService
+}
+
+func NewMFAHandler() *MFAHandler {
+	return &MFAHandler{
+		service: services.NewMFAService(),
+	}
+}
+
+// mfaError maps 2FA errors onto responses, falling back to the account errors
+func mfaError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": err.Error(), "code": "invalid_mfa_code"})
+	case errors.Is(err, services.ErrMFATokenUsed):
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": err.Error(), "code": "mfa_token_used"})
+	case errors.Is(err, services.ErrMFAAlreadyEnabled), errors.Is(err, services.ErrMFANotEnrolled),
+		errors.Is(err, services.ErrMFAEnrollmentFirst), errors.Is(err, services.ErrMFAMandatory):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+	default:
+		return accountError(c, err)
+	}
+}
+
+// mfaToken identifies the "mfa pending" token of the request
+func mfaToken(claims *services.JWTClaims) *services.MFAToken {
+	token := &services.MFAToken{ID: claims.ID}
+	if claims.ExpiresAt != nil {
+		token.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return token
+}
+
+type MFACodeRequest struct {
+	Code         string `json:"code" form:"code"`
+	RecoveryCode string `json:"recovery_code" form:"recovery_code"` // Instead of code, at login only
+}
+
+// POST /auth/mfa/enroll
+func (h *MFAHandler) Enroll(c echo.Context) error {
+	enrollment, err := h.service.Enroll(getUserID(c))
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// POST /auth/mfa/confirm
+func (h *MFAHandler) Confirm(c echo.Context) error {
+	req := new(MFACodeRequest)
+	if err := c.Bind(req); err != nil || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "code is required"})
+	}
+
+	// Admins enrolling during login get their session here
+	claims := c.Get("user").(*services.JWTClaims)
+	var pending *services.MFAToken
+	if claims.MFAPending {
+		pending = mfaToken(claims)
+	}
+	confirmation, err := h.service.Confirm(claims.UserID, req.Code, pending)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	response := echo.Map{"recovery_codes": confirmation.RecoveryCodes}
+	if confirmation.Tokens != nil {
+		response = sessionResponse(confirmation.Tokens, confirmation.User)
+		response["recovery_codes"] = confirmation.RecoveryCodes
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// POST /auth/mfa/verify
+func (h *MFAHandler) Verify(c echo.Context) error {
+	req := new(MFACodeRequest)
+	if err := c.Bind(req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "code or recovery_code is required"})
+	}
+
+	claims := c.Get("user").(*services.JWTClaims)
+	tokens, user, err := h.service.Verify(claims.UserID, *mfaToken(claims), req.Code, req.RecoveryCode)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, sessionResponse(tokens, user))
+}
+
+// POST /auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	req := new(MFACodeRequest)
+	if err := c.Bind(req); err != nil || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "code is required"})
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(getUserID(c), req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"recovery_codes": codes})
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" form:"password"`
+}
+
+// POST /auth/mfa/disable
+func (h *MFAHandler) Disable(c echo.Context) error {
+	req := new(DisableMFARequest)
+	if err := c.Bind(req); err != nil || req.Password == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "password is required"})
+	}
+
+	if err := h.service.Disable(getUserID(c), req.Password); err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Two-factor authentication disabled"})
+}
+
+// POST /admin/users/:id/mfa/reset
+func (h *MFAHandler) ResetUser(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Reset(uint(id)); err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Two-factor authentication reset"})
+}
@@ -1,49 +1,310 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
 	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
 )
 
-type NotificationHandler struct{}
+type NotificationHandler struct {
+	service *services.NotificationService
+}
 
 func NewNotificationHandler() *NotificationHandler {
-	return &NotificationHandler{}
+	return &NotificationHandler{
+		service: services.NewNotificationService(),
+	}
 }
 
 // GET /mobile/notifications
+// Newest first, ?limit= per page (100 at most). Pass the returned next_cursor
+// as ?cursor= for the next page. Filters: ?unread=true, ?category=, and
+// ?archived=true for the archive instead of the inbox.
 func (h *NotificationHandler) GetMyNotifications(c echo.Context) error {
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID := uint(claims["user_id"].(float64))
+	filter := services.InboxFilter{
+		Limit:      limitParam(c, 20),
+		UnreadOnly: c.QueryParam("unread") == "true",
+		Archived:   c.QueryParam("archived") == "true",
+		Category:   c.QueryParam("category"),
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		before, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid cursor"})
+		}
+		filter.Before = uint(before)
+	}
 
-	var notifications []models.Notification
-	if err := database.GetDB().Where("user_id = ?", userID).Order("created_at desc").Find(&notifications).Error; err != nil {
+	page, err := h.service.Inbox(getUserID(c), filter)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch notifications"})
 	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// GET /mobile/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c echo.Context) error {
+	count, err := h.service.UnreadCount(getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to count notifications"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"unread": count})
+}
+
+// NotificationIDsRequest names the notifications a bulk action applies to
+type NotificationIDsRequest struct {
+	IDs []uint `json:"ids"`
+}
 
-	return c.JSON(http.StatusOK, notifications)
+// bindNotificationIDs reads the IDs of a bulk action, at most 100
+func bindNotificationIDs(c echo.Context) ([]uint, error) {
+	req := new(NotificationIDsRequest)
+	if err := c.Bind(req); err != nil {
+		return nil, errors.New("ids must be a list of notification IDs")
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > 100 {
+		return nil, errors.New("ids must list between 1 and 100 notifications")
+	}
+	return req.IDs, nil
 }
 
 // POST /mobile/notifications/:id/read
 func (h *NotificationHandler) MarkNotificationRead(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID := uint(claims["user_id"].(float64))
+	userID := getUserID(c)
 
 	var notification models.Notification
 	if err := database.GetDB().Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "Notification not found"})
 	}
-
-	notification.IsRead = true
-	database.GetDB().Save(&notification)
+	if _, err := h.service.MarkRead(userID, []uint{notification.ID}); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update notification"})
+	}
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "Notification marked as read"})
 }
+
+// POST /mobile/notifications/read
+// Body: {"ids": [1, 2, 3]}
+func (h *NotificationHandler) MarkNotificationsRead(c echo.Context) error {
+	ids, err := bindNotificationIDs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	updated, err := h.service.MarkRead(getUserID(c), ids)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update notifications"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"updated": updated})
+}
+
+// POST /mobile/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	updated, err := h.service.MarkRead(getUserID(c), nil)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update notifications"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"updated": updated})
+}
+
+// POST /mobile/notifications/archive
+// Body: {"ids": [1, 2, 3]}
+func (h *NotificationHandler) ArchiveNotifications(c echo.Context) error {
+	return h.archive(c, true)
+}
+
+// POST /mobile/notifications/unarchive
+// Body: {"ids": [1, 2, 3]}
+func (h *NotificationHandler) UnarchiveNotifications(c echo.Context) error {
+	return h.archive(c, false)
+}
+
+func (h *NotificationHandler) archive(c echo.Context, archived bool) error {
+	ids, err := bindNotificationIDs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	updated, err := h.service.Archive(getUserID(c), ids, archived)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update notifications"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"updated": updated})
+}
+
+// DELETE /mobile/notifications/:id
+func (h *NotificationHandler) DeleteNotification(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	deleted, err := h.service.Delete(getUserID(c), []uint{uint(id)})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete notification"})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Notification not found"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Notification deleted"})
+}
+
+// POST /mobile/notifications/delete
+// Body: {"ids": [1, 2, 3]}
+func (h *NotificationHandler) DeleteNotifications(c echo.Context) error {
+	ids, err := bindNotificationIDs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	deleted, err := h.service.Delete(getUserID(c), ids)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete notifications"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"deleted": deleted})
+}
+
+// GET /mobile/notifications/email
+func (h *NotificationHandler) GetEmailSettings(c echo.Context) error {
+	mode, err := services.NewEmailNotificationService().Mode(getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch email settings"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"mode": mode})
+}
+
+// PUT /mobile/notifications/email
+// Body: {"mode": "off" | "instant" | "digest"}
+func (h *NotificationHandler) UpdateEmailSettings(c echo.Context) error {
+	var req struct {
+		Mode string `json:"mode" form:"mode"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	err := services.NewEmailNotificationService().SetMode(getUserID(c), req.Mode)
+	switch {
+	case errors.Is(err, services.ErrInvalidEmailMode), errors.Is(err, services.ErrNoEmailAddress),
+		errors.Is(err, services.ErrInvalidEmail):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update email settings"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"mode": req.Mode})
+}
+
+// streamBatch caps how many notifications are read per query while catching up
+const streamBatch = 100
+
+// POST /mobile/notifications/stream/ticket
+// A single-use ticket for opening the stream as ?ticket=, for browsers
+// whose EventSource can't send the Authorization header
+func (h *NotificationHandler) StreamTicket(c echo.Context) error {
+	ticket, err := services.NewAuthService().IssueStreamTicket(c.Get("user").(*services.JWTClaims))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to issue stream ticket"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"ticket": ticket, "expires_in": int(services.StreamTicketTTL().Seconds())})
+}
+
+// GET /mobile/notifications/stream
+// Server-Sent Events: every new notification is sent as a "notification"
+// event with its ID, so a client that reconnects with Last-Event-ID gets
+// what it missed. A stream without it starts from now.
+func (h *NotificationHandler) Stream(c echo.Context) error {
+	claims := c.Get("user").(*services.JWTClaims)
+	userID := claims.UserID
+
+	resume := c.Request().Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = c.QueryParam("last_event_id")
+	}
+	var lastID uint
+	if resume != "" {
+		id, err := strconv.ParseUint(resume, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid Last-Event-ID"})
+		}
+		lastID = uint(id)
+	}
+
+	// Subscribe before reading, so nothing created in between is missed
+	sub := h.service.Subscribe(userID)
+	defer sub.Close()
+
+	if resume == "" {
+		latest, err := h.service.LatestID(userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to open stream"})
+		}
+		lastID = latest
+	}
+
+	openEventStream(c, utils.EnvDuration("NOTIFICATION_STREAM_RETRY", 3*time.Second))
+	res := c.Response()
+
+	// send writes every notification after lastID
+	send := func() error {
+		for {
+			notifications, err := h.service.Since(userID, lastID, streamBatch)
+			if err != nil {
+				return err
+			}
+			for _, n := range notifications {
+				if err := writeEvent(res, strconv.FormatUint(uint64(n.ID), 10), "notification", n); err != nil {
+					return err
+				}
+				lastID = n.ID
+			}
+			if len(notifications) < streamBatch {
+				return nil
+			}
+		}
+	}
+	if err := send(); err != nil {
+		log.Printf("Notification stream for user %d: %v", userID, err)
+		return nil
+	}
+
+	// Polling catches notifications created on other instances and keeps
+	// proxies from closing an idle connection
+	poll := time.NewTicker(utils.EnvDuration("NOTIFICATION_STREAM_POLL", 15*time.Second))
+	defer poll.Stop()
+
+	// The stream ends with the access token; the app reconnects with a fresh one
+	var expired <-chan time.Time
+	if claims.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-expired:
+			writeEvent(res, "", "token_expired", echo.Map{})
+			return nil
+		case _, ok := <-sub.C:
+			if !ok {
+				return nil // Replaced by a newer stream of the same user
+			}
+		case <-poll.C:
+			if err := services.Accounts().Check(userID); err != nil {
+				return nil
+			}
+			if err := writePing(res); err != nil {
+				return nil
+			}
+		}
+		if err := send(); err != nil {
+			log.Printf("Notification stream for user %d: %v", userID, err)
+			return nil
+		}
+	}
+}
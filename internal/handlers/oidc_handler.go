@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+// oidcFlow is what the handler needs of services.OIDCService
+type oidcFlow interface {
+	Providers() []string
+	Begin(ctx context.Context, providerName string, linkUserID *uint, teamName string) (string, string, error)
+	Complete(ctx context.Context, providerName, code, state, boundState string, userID *uint) (*services.OIDCResult, error)
+	Identities(userID uint) ([]models.UserIdentity, error)
+	Unlink(userID, identityID uint) error
+}
+
+type OIDCHandler struct {
+	service oidcFlow
+}
+
+func NewOIDCHandler() *OIDCHandler {
+	return &OIDCHandler{
+		service: services.NewOIDCService(),
+	}
+}
+
+// oidcError maps sign-in errors onto responses, falling back to the account errors
+func oidcError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidOIDCState):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error(), "code": "invalid_state"})
+	case errors.Is(err, services.ErrIdentityLinked), errors.Is(err, services.ErrAdminLinkRequired):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error(), "code": "identity_conflict"})
+	case errors.Is(err, services.ErrProvisioningOff):
+		return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "no_account"})
+	case errors.Is(err, services.ErrAccountInactive), errors.Is(err, services.ErrAccountBanned):
+		return accountError(c, err)
+	default:
+		return c.JSON(http.StatusBadGateway, echo.Map{"error": "Sign-in with the identity provider failed"})
+	}
+}
+
+// oidcStateCookie binds a sign-in attempt to the browser that started it, so
+// a callback URL can't be replayed in someone else's browser
+const oidcStateCookie = "oidc_state"
+
+// oidcCookiePath scopes the state cookie to the OIDC routes, wherever they
+// are mounted: the route's path up to and including "/oidc"
+func oidcCookiePath(c echo.Context) string {
+	path := c.Path()
+	if i := strings.Index(path, "/oidc/"); i >= 0 {
+		return path[:i+len("/oidc")]
+	}
+	return "/"
+}
+
+func setStateCookie(c echo.Context, state string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath(c),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		// Lax, as the provider sends the browser back with a cross-site redirect
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// authorizationResponse binds the state to the browser and returns the
+// provider URL, or redirects to it for browsers (?redirect=true)
+func authorizationResponse(c echo.Context, url, state string) error {
+	setStateCookie(c, state, int(services.OIDCLoginStateTTL.Seconds()))
+	if c.QueryParam("redirect") == "true" {
+		return c.Redirect(http.StatusFound, url)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"authorization_url": url})
+}
+
+// callbackResponse answers a completed callback: the new session for a
+// sign-in, the identity for linking (which keeps the current session)
+func callbackResponse(c echo.Context, result *services.OIDCResult) error {
+	if result.Login == nil {
+		return c.JSON(http.StatusOK, echo.Map{"message": "Identity linked", "identity": result.Identity})
+	}
+	response := loginResponse(result.Login)
+	response["created"] = result.Created
+	return c.JSON(http.StatusOK, response)
+}
+
+// GET /auth/oidc/providers
+func (h *OIDCHandler) GetProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.Providers())
+}
+
+// GET /auth/oidc/:provider/login?team_name=
+func (h *OIDCHandler) Login(c echo.Context) error {
+	url, state, err := h.service.Begin(c.Request().Context(), c.Param("provider"), nil, c.QueryParam("team_name"))
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return authorizationResponse(c, url, state)
+}
+
+// GET /auth/oidc/:provider/callback
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": providerErr, "error_description": c.QueryParam("error_description")})
+	}
+
+	boundState := ""
+	if cookie, err := c.Cookie(oidcStateCookie); err == nil {
+		boundState = cookie.Value
+	}
+	setStateCookie(c, "", -1)
+
+	result, err := h.service.Complete(c.Request().Context(), c.Param("provider"), c.QueryParam("code"), c.QueryParam("state"), boundState, nil)
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return callbackResponse(c, result)
+}
+
+// POST /auth/oidc/:provider/link
+// The app opens the returned URL, and sends the code and state the provider
+// hands back to the link callback with the user's token
+func (h *OIDCHandler) Link(c echo.Context) error {
+	userID := getUserID(c)
+	url, _, err := h.service.Begin(c.Request().Context(), c.Param("provider"), &userID, "")
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"authorization_url": url})
+}
+
+type LinkCallbackRequest struct {
+	Code  string `json:"code" form:"code"`
+	State string `json:"state" form:"state"`
+}
+
+// POST /auth/oidc/:provider/link/callback
+// Only the user who started the link can complete it
+func (h *OIDCHandler) LinkCallback(c echo.Context) error {
+	req := new(LinkCallbackRequest)
+	if err := c.Bind(req); err != nil || req.Code == "" || req.State == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "code and state are required"})
+	}
+
+	userID := getUserID(c)
+	result, err := h.service.Complete(c.Request().Context(), c.Param("provider"), req.Code, req.State, "", &userID)
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return callbackResponse(c, result)
+}
+
+// GET /auth/identities
+func (h *OIDCHandler) GetIdentities(c echo.Context) error {
+	identities, err := h.service.Identities(getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch identities"})
+	}
+	return c.JSON(http.StatusOK, identities)
+}
+
+// DELETE /auth/identities/:id
+func (h *OIDCHandler) Unlink(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	err := h.service.Unlink(getUserID(c), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Identity not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to unlink identity"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Identity unlinked"})
+}
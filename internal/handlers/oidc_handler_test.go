@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+)
+
+// fakeOIDC hands out one attempt and completes it only when the callback
+// carries what the real service checks: the browser's state cookie for a
+// sign-in, the signed in user for a link
+type fakeOIDC struct {
+	state      string
+	linkUserID *uint
+	boundState string // As received by Complete
+}
+
+func (f *fakeOIDC) Providers() []string { return []string{"mock"} }
+
+func (f *fakeOIDC) Begin(ctx context.Context, providerName string, linkUserID *uint, teamName string) (string, string, error) {
+	f.state, f.linkUserID = "state-1", linkUserID
+	return "https://idp.example.com/authorize?state=state-1", f.state, nil
+}
+
+func (f *fakeOIDC) Complete(ctx context.Context, providerName, code, state, boundState string, userID *uint) (*services.OIDCResult, error) {
+	f.boundState = boundState
+	if state != f.state {
+		return nil, services.ErrInvalidOIDCState
+	}
+	if f.linkUserID != nil {
+		if userID == nil || *userID != *f.linkUserID {
+			return nil, services.ErrInvalidOIDCState
+		}
+		return &services.OIDCResult{Identity: &models.UserIdentity{ID: 1, UserID: *userID}}, nil
+	}
+	if boundState != state {
+		return nil, services.ErrInvalidOIDCState
+	}
+	user := &models.User{ID: 1, Username: "captain"}
+	return &services.OIDCResult{
+		Login: &services.LoginResult{Tokens: &services.TokenPair{AccessToken: "access"}, User: user},
+		User:  user,
+	}, nil
+}
+
+func (f *fakeOIDC) Identities(userID uint) ([]models.UserIdentity, error) { return nil, nil }
+
+func (f *fakeOIDC) Unlink(userID, identityID uint) error { return nil }
+
+// newOIDCServer mounts the OIDC routes where main does, with a stand-in for
+// the auth middleware that signs in the user of the X-User header
+func newOIDCServer(t *testing.T, flow *fakeOIDC) *httptest.Server {
+	h := &OIDCHandler{service: flow}
+	signedIn := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := uint(7)
+			if c.Request().Header.Get("X-User") == "8" {
+				id = 8
+			}
+			c.Set("user", &services.JWTClaims{UserID: id})
+			return next(c)
+		}
+	}
+
+	e := echo.New()
+	auth := e.Group("/api/v1/auth")
+	auth.GET("/oidc/:provider/login", h.Login)
+	auth.GET("/oidc/:provider/callback", h.Callback)
+	auth.POST("/oidc/:provider/link", h.Link, signedIn)
+	auth.POST("/oidc/:provider/link/callback", h.LinkCallback, signedIn)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func browser(t *testing.T) *http.Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Jar: jar}
+}
+
+func TestOIDCLoginCallbackGetsStateCookie(t *testing.T) {
+	flow := &fakeOIDC{}
+	server := newOIDCServer(t, flow)
+	client := browser(t)
+
+	res, err := client.Get(server.URL + "/api/v1/auth/oidc/mock/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == oidcStateCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Path != "/api/v1/auth/oidc" || !cookie.HttpOnly {
+		t.Fatalf("unexpected state cookie %+v", cookie)
+	}
+
+	res, err = client.Get(server.URL + "/api/v1/auth/oidc/mock/callback?code=good-code&state=state-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("callback answered %d", res.StatusCode)
+	}
+	if flow.boundState != "state-1" {
+		t.Errorf("callback got state cookie %q, want state-1", flow.boundState)
+	}
+	var body map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&body)
+	if body["access_token"] != "access" {
+		t.Errorf("unexpected response %v", body)
+	}
+}
+
+func TestOIDCLoginCallbackFromAnotherBrowser(t *testing.T) {
+	flow := &fakeOIDC{}
+	server := newOIDCServer(t, flow)
+
+	res, err := browser(t).Get(server.URL + "/api/v1/auth/oidc/mock/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	res, err = browser(t).Get(server.URL + "/api/v1/auth/oidc/mock/callback?code=good-code&state=state-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("callback answered %d, want %d", res.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestOIDCLinkIsCompletedByTheSameUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		completeAs string
+		want       int
+	}{
+		{name: "same user", completeAs: "7", want: http.StatusOK},
+		{name: "another user", completeAs: "8", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := &fakeOIDC{}
+			server := newOIDCServer(t, flow)
+
+			res, err := http.Post(server.URL+"/api/v1/auth/oidc/mock/link", "application/json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if len(res.Cookies()) != 0 {
+				t.Errorf("link set cookies %v", res.Cookies())
+			}
+
+			form := url.Values{"code": {"good-code"}, "state": {"state-1"}}
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/auth/oidc/mock/link/callback", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-User", tt.completeAs)
+			res, err = http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("link callback answered %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+)
+
+type PlayerHandler struct{}
+
+func NewPlayerHandler() *PlayerHandler {
+	return &PlayerHandler{}
+}
+
+// Helper to load the Player record linked to the logged in player account
+func getMyPlayer(c echo.Context) (*models.Player, error) {
+	claims := c.Get("user").(*services.JWTClaims)
+	var user models.User
+	if err := database.GetDB().First(&user, claims.UserID).Error; err != nil || user.PlayerID == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "No player linked to this account")
+	}
+
+	var player models.Player
+	if err := database.GetDB().First(&player, *user.PlayerID).Error; err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Player not found")
+	}
+	return &player, nil
+}
+
+// GET /me
+func (h *PlayerHandler) GetProfile(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, player)
+}
+
+// PUT /me
+type UpdateProfileRequest struct {
+	Position     string `json:"position" form:"position"`
+	Availability string `json:"availability" form:"availability"`
+}
+
+func (h *PlayerHandler) UpdateProfile(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(UpdateProfileRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request"})
+	}
+
+	if req.Position != "" {
+		player.Position = req.Position
+	}
+	if req.Availability != "" {
+		switch req.Availability {
+		case "available", "injured", "unavailable":
+			player.Availability = req.Availability
+		default:
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "availability must be available, injured or unavailable"})
+		}
+	}
+
+	database.GetDB().Save(player)
+	return c.JSON(http.StatusOK, player)
+}
+
+// POST /me/photo
+func (h *PlayerHandler) UploadPhoto(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+
+	maxSize := int64(utils.EnvInt("PHOTO_MAX_BYTES", 5<<20))
+	// Leave room for the rest of the form
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+64<<10)
+
+	file, err := c.FormFile("image")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{"error": utils.ErrFileTooLarge.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "image file is required"})
+	}
+
+	path, err := utils.SaveUploadedImage(file, "uploads/players", maxSize)
+	switch {
+	case errors.Is(err, utils.ErrNotAnImage):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case errors.Is(err, utils.ErrFileTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to save image"})
+	}
+
+	player.ImageURL = "/" + strings.ReplaceAll(path, "\\", "/")
+	database.GetDB().Save(player)
+	return c.JSON(http.StatusOK, player)
+}
+
+// GET /me/fixtures
+func (h *PlayerHandler) GetFixtures(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+	if player.TeamID == nil {
+		return c.JSON(http.StatusOK, []models.Match{})
+	}
+
+	var matches []models.Match
+	if err := database.GetDB().Preload("TeamA").Preload("TeamB").
+		Where("(team_a_id = ? OR team_b_id = ?) AND status <> ?", *player.TeamID, *player.TeamID, "completed").
+		Order("scheduled_at IS NULL, scheduled_at, id").
+		Find(&matches).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch fixtures"})
+	}
+	return c.JSON(http.StatusOK, matches)
+}
+
+// GET /me/stats
+func (h *PlayerHandler) GetStats(c echo.Context) error {
+	player, err := getMyPlayer(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	stat, err := services.NewStatsService().PlayerStatsFor(player.ID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch stats"})
+	}
+	return c.JSON(http.StatusOK, stat)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
 	"github.com/yourname/leaguemaster/pkg/database"
 )
 
@@ -83,3 +84,13 @@ func (h *PublicHandler) GetPlayer(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, player)
 }
+
+// GET /players/:id/teams
+func (h *PublicHandler) GetPlayerTeams(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	memberships, err := services.NewPlayerService().Memberships(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch team history"})
+	}
+	return c.JSON(http.StatusOK, memberships)
+}
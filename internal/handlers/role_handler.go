@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+type RoleHandler struct {
+	service *services.PermissionService
+}
+
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		service: services.NewPermissionService(),
+	}
+}
+
+// GET /admin/roles
+func (h *RoleHandler) GetRoles(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.Roles())
+}
+
+// GET /admin/me/permissions
+func (h *RoleHandler) GetMyPermissions(c echo.Context) error {
+	perms, err := h.service.Permissions(getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch permissions"})
+	}
+	return c.JSON(http.StatusOK, perms)
+}
+
+// GET /admin/role-assignments?user_id=
+func (h *RoleHandler) GetAssignments(c echo.Context) error {
+	userID, _ := strconv.Atoi(c.QueryParam("user_id"))
+	assignments, err := h.service.Assignments(uint(userID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch role assignments"})
+	}
+	return c.JSON(http.StatusOK, assignments)
+}
+
+type RoleAssignmentRequest struct {
+	UserID       uint   `json:"user_id" form:"user_id"`
+	Role         string `json:"role" form:"role"`
+	TournamentID *uint  `json:"tournament_id" form:"tournament_id"` // Omit for a league-wide role
+}
+
+// POST /admin/role-assignments
+func (h *RoleHandler) AssignRole(c echo.Context) error {
+	req := new(RoleAssignmentRequest)
+	if err := c.Bind(req); err != nil || req.UserID == 0 || req.Role == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id and role are required"})
+	}
+
+	grantedBy := getUserID(c)
+	assignment, err := h.service.Assign(req.UserID, req.Role, req.TournamentID, &grantedBy)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "User or tournament not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, assignment)
+}
+
+// DELETE /admin/role-assignments/:id
+func (h *RoleHandler) RevokeRole(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	err := h.service.Revoke(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Role assignment not found"})
+	}
+	if errors.Is(err, services.ErrLastSuperAdmin) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to revoke role"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Role revoked"})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeasonHandler struct {
+	service *services.SeasonService
+}
+
+func NewSeasonHandler() *SeasonHandler {
+	return &SeasonHandler{
+		service: services.NewSeasonService(),
+	}
+}
+
+// Public
+
+// GET /seasons
+func (h *SeasonHandler) GetSeasons(c echo.Context) error {
+	var seasons []models.Season
+	if err := database.GetDB().Order("id desc").Find(&seasons).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch seasons"})
+	}
+	return c.JSON(http.StatusOK, seasons)
+}
+
+// GET /seasons/:id
+func (h *SeasonHandler) GetSeason(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var season models.Season
+	if err := database.GetDB().Preload("Tournaments").First(&season, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+	}
+	return c.JSON(http.StatusOK, season)
+}
+
+// GET /seasons/:id/standings
+func (h *SeasonHandler) GetArchivedStandings(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var standings []models.ArchivedStanding
+	if err := database.GetDB().Where("season_id = ?", id).Order("tournament_id, `rank`").Find(&standings).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch standings"})
+	}
+	return c.JSON(http.StatusOK, standings)
+}
+
+// GET /seasons/:id/stats
+func (h *SeasonHandler) GetArchivedStats(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	q := database.GetDB().Where("season_id = ?", id)
+	if tournamentID := c.QueryParam("tournament_id"); tournamentID != "" {
+		q = q.Where("tournament_id = ?", tournamentID)
+	}
+
+	var stats []models.ArchivedPlayerStat
+	if err := q.Order("goals desc, assists desc").Find(&stats).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch stats"})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GET /seasons/:id/squads
+func (h *SeasonHandler) GetSquads(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var squads []models.SquadSnapshot
+	if err := database.GetDB().Where("season_id = ?", id).Order("team_id, jersey_number").Find(&squads).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch squads"})
+	}
+	return c.JSON(http.StatusOK, squads)
+}
+
+// GET /players/:id/history
+func (h *SeasonHandler) GetPlayerHistory(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	history, err := h.service.PlayerHistory(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch player history"})
+	}
+	return c.JSON(http.StatusOK, history)
+}
+
+// Admin
+
+// POST /admin/seasons
+func (h *SeasonHandler) CreateSeason(c echo.Context) error {
+	var season models.Season
+	if err := c.Bind(&season); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+	if season.Status == "archived" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Use the archive endpoint to archive a season"})
+	}
+	if err := database.GetDB().Create(&season).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create season"})
+	}
+	return c.JSON(http.StatusCreated, season)
+}
+
+type UpdateSeasonRequest struct {
+	Name      *string    `json:"name" form:"name"`
+	StartDate *time.Time `json:"start_date" form:"start_date"`
+	EndDate   *time.Time `json:"end_date" form:"end_date"`
+	Status    *string    `json:"status" form:"status"`
+}
+
+// PUT /admin/seasons/:id
+// Fields left out keep their value
+func (h *SeasonHandler) UpdateSeason(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(UpdateSeasonRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+	if req.Status != nil && *req.Status == "archived" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Use the archive endpoint to archive a season"})
+	}
+	if req.Status != nil && *req.Status != "upcoming" && *req.Status != "active" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Status must be upcoming or active"})
+	}
+
+	var season models.Season
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&season, id).Error; err != nil {
+			return err
+		}
+		if season.Status == "archived" {
+			return services.ErrSeasonArchived
+		}
+
+		if req.Name != nil {
+			season.Name = *req.Name
+		}
+		if req.StartDate != nil {
+			season.StartDate = req.StartDate
+		}
+		if req.EndDate != nil {
+			season.EndDate = req.EndDate
+		}
+		if req.Status != nil {
+			season.Status = *req.Status
+		}
+		return tx.Omit("Tournaments").Save(&season).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+	}
+	if errors.Is(err, services.ErrSeasonArchived) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "Season is archived"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update season"})
+	}
+	return c.JSON(http.StatusOK, season)
+}
+
+// DELETE /admin/seasons/:id
+func (h *SeasonHandler) DeleteSeason(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	err := h.service.DeleteSeason(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+	}
+	if errors.Is(err, services.ErrSeasonArchived) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "Archived seasons cannot be deleted"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete season"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Season deleted"})
+}
+
+// POST /admin/seasons/:id/snapshot
+func (h *SeasonHandler) SnapshotSquads(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	err := h.service.SnapshotSquads(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+	}
+	if errors.Is(err, services.ErrSeasonArchived) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "Season is archived"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to snapshot squads"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Squads snapshotted"})
+}
+
+// POST /admin/seasons/:id/archive
+func (h *SeasonHandler) ArchiveSeason(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	season, err := h.service.ArchiveSeason(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Season not found"})
+	}
+	if errors.Is(err, services.ErrSeasonArchived) {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "Season is already archived"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to archive season"})
+	}
+	return c.JSON(http.StatusOK, season)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openEventStream writes the Server-Sent Events headers and the reconnect delay
+func openEventStream(c echo.Context, retry time.Duration) {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprintf(res, "retry: %d\n\n", retry.Milliseconds())
+	res.Flush()
+}
+
+// writeEvent sends one event with a JSON payload. An empty id leaves the
+// client's Last-Event-ID as it was.
+func writeEvent(res *echo.Response, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(res, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+// writePing keeps an idle stream from being closed by proxies
+func writePing(res *echo.Response) error {
+	if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+type StatsHandler struct {
+	service *services.StatsService
+}
+
+func NewStatsHandler() *StatsHandler {
+	return &StatsHandler{
+		service: services.NewStatsService(),
+	}
+}
+
+// parseDateParam accepts either a plain date (2006-01-02) or RFC3339.
+// A plain "to" date is treated as inclusive of the whole day.
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// Reads ?tournament_id=&from=&to= into a StatsFilter
+func bindStatsFilter(c echo.Context) (services.StatsFilter, error) {
+	var filter services.StatsFilter
+
+	if raw := c.QueryParam("tournament_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return filter, errors.New("invalid tournament_id")
+		}
+		tournamentID := uint(id)
+		filter.TournamentID = &tournamentID
+	}
+
+	from, err := parseDateParam(c.QueryParam("from"), false)
+	if err != nil {
+		return filter, errors.New("invalid from date")
+	}
+	to, err := parseDateParam(c.QueryParam("to"), true)
+	if err != nil {
+		return filter, errors.New("invalid to date")
+	}
+	filter.From = from
+	filter.To = to
+
+	return filter, nil
+}
+
+func limitParam(c echo.Context, fallback int) int {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		return fallback
+	}
+	return limit
+}
+
+// GET /stats/players
+func (h *StatsHandler) GetPlayerStats(c echo.Context) error {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	stats, err := h.service.PlayerStats(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch player stats"})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GET /players/:id/stats
+func (h *StatsHandler) GetPlayerStat(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	stat, err := h.service.PlayerStatsFor(uint(id), filter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch player stats"})
+	}
+	return c.JSON(http.StatusOK, stat)
+}
+
+// GET /leaderboards/golden-boot
+func (h *StatsHandler) GetGoldenBoot(c echo.Context) error {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	board, err := h.service.GoldenBoot(filter, limitParam(c, 10))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch leaderboard"})
+	}
+	return c.JSON(http.StatusOK, board)
+}
+
+// GET /leaderboards/assists
+func (h *StatsHandler) GetTopAssists(c echo.Context) error {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	board, err := h.service.TopAssists(filter, limitParam(c, 10))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch leaderboard"})
+	}
+	return c.JSON(http.StatusOK, board)
+}
+
+// GET /leaderboards/fair-play
+func (h *StatsHandler) GetFairPlay(c echo.Context) error {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	board, err := h.service.FairPlay(filter, limitParam(c, 0))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch leaderboard"})
+	}
+	return c.JSON(http.StatusOK, board)
+}
+
+// GET /teams/:id/stats
+func (h *StatsHandler) GetTeamStats(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	stats, err := h.service.TeamStats(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch team stats"})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GET /teams/:id/head-to-head/:opponent_id
+func (h *StatsHandler) GetHeadToHead(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	opponentID, _ := strconv.Atoi(c.Param("opponent_id"))
+	if id == opponentID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Pick two different teams"})
+	}
+
+	h2h, err := h.service.HeadToHead(uint(id), uint(opponentID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Team not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch head-to-head record"})
+	}
+	return c.JSON(http.StatusOK, h2h)
+}
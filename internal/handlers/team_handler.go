@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+)
+
+type TeamHandler struct {
+	service *services.TeamService
+}
+
+func NewTeamHandler() *TeamHandler {
+	return &TeamHandler{
+		service: services.NewTeamService(),
+	}
+}
+
+// GET /my-teams
+func (h *TeamHandler) GetMyTeams(c echo.Context) error {
+	teams, err := h.service.UserTeams(getUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch teams"})
+	}
+	return c.JSON(http.StatusOK, teams)
+}
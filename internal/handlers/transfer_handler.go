@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+type TransferHandler struct {
+	service *services.TransferService
+}
+
+func NewTransferHandler() *TransferHandler {
+	return &TransferHandler{
+		service: services.NewTransferService(),
+	}
+}
+
+// transferError maps service errors onto HTTP responses
+func transferError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Transfer not found"})
+	case errors.Is(err, services.ErrTransferForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrTransferNotPending), errors.Is(err, services.ErrTransferWindowClosed),
+		errors.Is(err, services.ErrAlreadyInTeam), errors.Is(err, services.ErrTransferPending),
+		errors.Is(err, services.ErrPlayerMoved):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to process transfer"})
+	}
+}
+
+// Captain
+
+// GET /mobile/transfers
+func (h *TransferHandler) GetMyTransfers(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	var transfers []models.Transfer
+	if err := database.GetDB().Preload("Player").Where("from_team_id = ? OR to_team_id = ?", teamID, teamID).Order("created_at desc").Find(&transfers).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch transfers"})
+	}
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// POST /mobile/transfers
+type TransferRequest struct {
+	PlayerID uint   `json:"player_id" form:"player_id"`
+	Note     string `json:"note" form:"note"`
+}
+
+func (h *TransferHandler) RequestTransfer(c echo.Context) error {
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	req := new(TransferRequest)
+	if err := c.Bind(req); err != nil || req.PlayerID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid transfer request"})
+	}
+
+	transfer, err := h.service.RequestTransfer(getUserID(c), teamID, req.PlayerID, req.Note)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Player not found"})
+	}
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusCreated, transfer)
+}
+
+// POST /mobile/transfers/:id/release
+func (h *TransferHandler) ReleasePlayer(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	transfer, err := h.service.ReleaseTransfer(uint(id), teamID, getUserID(c))
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// POST /mobile/transfers/:id/decline
+func (h *TransferHandler) DeclineTransfer(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	teamID, ok := getCaptainTeamID(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "No team assigned"})
+	}
+
+	transfer, err := h.service.DeclineTransfer(uint(id), teamID)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// Public
+
+// GET /players/:id/transfers
+func (h *TransferHandler) GetPlayerTransfers(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var transfers []models.Transfer
+	if err := database.GetDB().Where("player_id = ? AND status = ?", id, "approved").Order("reviewed_at desc").Find(&transfers).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch transfers"})
+	}
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// GET /transfer-windows
+func (h *TransferHandler) GetTransferWindows(c echo.Context) error {
+	var windows []models.TransferWindow
+	if err := database.GetDB().Order("opens_at desc").Find(&windows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch transfer windows"})
+	}
+	return c.JSON(http.StatusOK, windows)
+}
+
+// Admin
+
+// GET /admin/transfers
+func (h *TransferHandler) GetAllTransfers(c echo.Context) error {
+	q := database.GetDB().Preload("Player").Order("created_at desc")
+	if status := c.QueryParam("status"); status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var transfers []models.Transfer
+	if err := q.Find(&transfers).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to fetch transfers"})
+	}
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// POST /admin/transfers/:id/approve
+func (h *TransferHandler) ApproveTransfer(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	transfer, err := h.service.ApproveTransfer(uint(id), getUserID(c))
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// POST /admin/transfers/:id/reject
+func (h *TransferHandler) RejectTransfer(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	type RejectRequest struct {
+		Note string `json:"note" form:"note"`
+	}
+	req := new(RejectRequest)
+	c.Bind(req)
+
+	transfer, err := h.service.RejectTransfer(uint(id), getUserID(c), req.Note)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// POST /admin/transfer-windows
+func (h *TransferHandler) CreateTransferWindow(c echo.Context) error {
+	var window models.TransferWindow
+	if err := c.Bind(&window); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+	if window.OpensAt.IsZero() || !window.ClosesAt.After(window.OpensAt) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "closes_at must be after opens_at"})
+	}
+	if err := database.GetDB().Create(&window).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create transfer window"})
+	}
+	return c.JSON(http.StatusCreated, window)
+}
+
+// PUT /admin/transfer-windows/:id
+func (h *TransferHandler) UpdateTransferWindow(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var window models.TransferWindow
+	if err := database.GetDB().First(&window, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Transfer window not found"})
+	}
+	if err := c.Bind(&window); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+	if !window.ClosesAt.After(window.OpensAt) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "closes_at must be after opens_at"})
+	}
+	database.GetDB().Save(&window)
+	return c.JSON(http.StatusOK, window)
+}
+
+// DELETE /admin/transfer-windows/:id
+func (h *TransferHandler) DeleteTransferWindow(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := database.GetDB().Delete(&models.TransferWindow{}, id).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete transfer window"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Transfer window deleted"})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/services"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		service: services.NewWebhookService(),
+	}
+}
+
+func webhookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhook):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Webhook, delivery or tournament not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to process webhook"})
+	}
+}
+
+// GET /admin/webhooks/events
+func (h *WebhookHandler) GetEvents(c echo.Context) error {
+	return c.JSON(http.StatusOK, services.WebhookEvents)
+}
+
+// GET /admin/webhooks
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	subs, err := h.service.List()
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+// POST /admin/webhooks
+// The signing secret is only returned here (and when rotated)
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	req := new(services.WebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	sub, secret, err := h.service.Create(*req, getUserID(c))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"webhook": sub, "secret": secret})
+}
+
+// PUT /admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	req := new(services.WebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+	}
+
+	sub, err := h.service.Update(uint(id), *req)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+// DELETE /admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Delete(uint(id)); err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "Webhook deleted"})
+}
+
+// POST /admin/webhooks/:id/rotate-secret
+func (h *WebhookHandler) RotateSecret(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	secret, err := h.service.RotateSecret(uint(id))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"secret": secret})
+}
+
+// GET /admin/webhooks/:id/deliveries
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	deliveries, err := h.service.Deliveries(uint(id), min(limitParam(c, 50), 200))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// POST /admin/webhooks/:id/test
+// Posts a test event right away and returns the logged delivery, whether the
+// endpoint accepted it or not
+func (h *WebhookHandler) SendTest(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	delivery, err := h.service.SendTest(uint(id))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// POST /admin/webhooks/:id/deliveries/:delivery_id/retry
+func (h *WebhookHandler) RetryDelivery(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	deliveryID, _ := strconv.Atoi(c.Param("delivery_id"))
+	delivery, err := h.service.Redeliver(uint(id), uint(deliveryID))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
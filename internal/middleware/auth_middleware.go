@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -11,26 +12,114 @@ import (
 )
 
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return authenticate(next, authOptions{})
+}
+
+// PasswordChangeAuth is AuthMiddleware for the change-password route, which
+// has to stay reachable while a password change is pending.
+func PasswordChangeAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return authenticate(next, authOptions{allowPasswordChange: true})
+}
+
+// MFAPendingAuth only accepts the "mfa pending" token handed out by login,
+// for the second login step.
+func MFAPendingAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return authenticate(next, authOptions{mfaPending: mfaOnly})
+}
+
+// MFAEnrollAuth accepts full and "mfa pending" tokens, so 2FA can be set up
+// from the settings or, for admins, in the middle of logging in.
+func MFAEnrollAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return authenticate(next, authOptions{allowPasswordChange: true, mfaPending: mfaAllowed})
+}
+
+// StreamAuth is AuthMiddleware for event streams. Browsers' EventSource can't
+// set headers, so they may pass a single-use stream ticket as ?ticket=
+// instead; the access token itself never goes in a URL, where it gets logged.
+func StreamAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return authenticate(next, authOptions{allowTicket: true})
+}
+
+const (
+	mfaRefused = iota
+	mfaAllowed
+	mfaOnly
+)
+
+type authOptions struct {
+	allowPasswordChange bool
+	mfaPending          int // Whether "mfa pending" tokens are refused, allowed or required
+	allowTicket         bool
+}
+
+// bearerClaims reads the claims of the request's bearer token
+func bearerClaims(c echo.Context) (*services.JWTClaims, error) {
+	authHeader := c.Request().Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Missing Authorization header")
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
+	}
+
+	tokenString := parts[1]
+	claims := &services.JWTClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+	}
+	return claims, nil
+}
+
+func authenticate(next echo.HandlerFunc, opts authOptions) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Missing Authorization header")
+		var claims *services.JWTClaims
+		var err error
+		if ticket := c.QueryParam("ticket"); opts.allowTicket && ticket != "" && c.Request().Header.Get("Authorization") == "" {
+			claims, err = services.NewAuthService().RedeemStreamTicket(ticket)
+			if errors.Is(err, services.ErrInvalidStreamTicket) {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify stream ticket")
+			}
+		} else if claims, err = bearerClaims(c); err != nil {
+			return err
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
+		// Tokens outlive bans and deactivations, so re-check the account (cached)
+		if err := services.Accounts().Check(claims.UserID); err != nil {
+			var ban *services.BanError
+			switch {
+			case errors.As(err, &ban):
+				return c.JSON(http.StatusForbidden, echo.Map{
+					"error":          err.Error(),
+					"code":           "account_banned",
+					"ban_reason":     ban.Reason,
+					"ban_expires_at": ban.ExpiresAt,
+				})
+			case errors.Is(err, services.ErrAccountInactive):
+				return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "account_inactive"})
+			default:
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify account")
+			}
 		}
 
-		tokenString := parts[1]
-		claims := &services.JWTClaims{}
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		switch {
+		case claims.MFAPending && opts.mfaPending == mfaRefused:
+			return c.JSON(http.StatusUnauthorized, echo.Map{"error": "Two-factor authentication required", "code": "mfa_required"})
+		case !claims.MFAPending && opts.mfaPending == mfaOnly:
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token for this step")
+		}
 
-		if err != nil || !token.Valid {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+		if claims.MustChangePassword && !opts.allowPasswordChange {
+			return c.JSON(http.StatusForbidden, echo.Map{"error": "Password change required", "code": "password_change_required"})
 		}
 
 		c.Set("user", claims)
@@ -57,3 +146,13 @@ func CaptainOnly(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+func PlayerOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user := c.Get("user").(*services.JWTClaims)
+		if user.Role != "player" {
+			return echo.NewHTTPError(http.StatusForbidden, "Players only")
+		}
+		return next(c)
+	}
+}
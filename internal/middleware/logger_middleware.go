@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+var credentialParam = regexp.MustCompile(`([?&](?:access_token|ticket)=)[^&]*`)
+
+// Logger is Echo's request logger with credentials passed in the query (the
+// ?ticket= of event streams, see StreamAuth, or a stray ?access_token= from
+// an old client) redacted from the logged URI
+func Logger() echo.MiddlewareFunc {
+	config := echoMiddleware.DefaultLoggerConfig
+	config.Format = strings.Replace(config.Format, "${uri}", "${custom}", 1)
+	config.CustomTagFunc = func(c echo.Context, buf *bytes.Buffer) (int, error) {
+		return buf.WriteString(credentialParam.ReplaceAllString(c.Request().RequestURI, "${1}[redacted]"))
+	}
+	return echoMiddleware.LoggerWithConfig(config)
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import "testing"
+
+func TestCredentialParamRedacted(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/mobile/notifications/stream?ticket=abc123":                   "/api/v1/mobile/notifications/stream?ticket=[redacted]",
+		"/api/v1/mobile/notifications/stream?last_event_id=4&ticket=abc&x=1":  "/api/v1/mobile/notifications/stream?last_event_id=4&ticket=[redacted]&x=1",
+		"/api/v1/mobile/notifications/stream?access_token=eyJhbGciOi.payload": "/api/v1/mobile/notifications/stream?access_token=[redacted]",
+		"/api/v1/public/matches?page=2":                                       "/api/v1/public/matches?page=2",
+	}
+	for uri, want := range tests {
+		if got := credentialParam.ReplaceAllString(uri, "${1}[redacted]"); got != want {
+			t.Errorf("%s logged as %s, want %s", uri, got, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+)
+
+// RequirePermission lets the request through only if the user holds every
+// listed permission league-wide. Use after AuthMiddleware.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*services.JWTClaims)
+			for _, permission := range permissions {
+				ok, err := services.NewPermissionService().HasPermission(user.UserID, permission, nil)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check permissions")
+				}
+				if !ok {
+					return echo.NewHTTPError(http.StatusForbidden, "Missing permission: "+permission)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// TournamentResolver finds the tournament a request acts on
+type TournamentResolver func(c echo.Context) (uint, error)
+
+// idParam parses a numeric ID path parameter
+func idParam(c echo.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+name)
+	}
+	return uint(id), nil
+}
+
+// TournamentParam reads the tournament ID from a path parameter
+func TournamentParam(name string) TournamentResolver {
+	return func(c echo.Context) (uint, error) {
+		return idParam(c, name)
+	}
+}
+
+// MatchTournament looks up the tournament of the match in a path parameter
+func MatchTournament(name string) TournamentResolver {
+	return func(c echo.Context) (uint, error) {
+		id, err := idParam(c, name)
+		if err != nil {
+			return 0, err
+		}
+		var match models.Match
+		if err := database.GetDB().Select("id", "tournament_id").Where("id = ?", id).First(&match).Error; err != nil {
+			return 0, err
+		}
+		return match.TournamentID, nil
+	}
+}
+
+// RequireTournamentPermission is RequirePermission that also accepts roles
+// scoped to the tournament the request acts on.
+func RequireTournamentPermission(permission string, resolve TournamentResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*services.JWTClaims)
+			tournamentID, err := resolve(c)
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				return httpErr
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusNotFound, "Tournament not found")
+			}
+
+			ok, err := services.NewPermissionService().HasPermission(user.UserID, permission, &tournamentID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check permissions")
+			}
+			if !ok {
+				return echo.NewHTTPError(http.StatusForbidden, "Missing permission: "+permission)
+			}
+			return next(c)
+		}
+	}
+}
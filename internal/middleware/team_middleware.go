@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/internal/services"
+	"github.com/yourname/leaguemaster/pkg/database"
+)
+
+// TeamHeader names the team a request acts on when the route has no :team_id
+const TeamHeader = "X-Team-ID"
+
+// TeamRole lets the request through if the user holds at least the given role
+// (captain > vice_captain > manager) on the team the request acts on. The team
+// comes from the :team_id path parameter, the X-Team-ID header or the user's
+// default team, in that order. It is stored in the context as "team_id" and
+// "team_role". Use after AuthMiddleware.
+func TeamRole(min string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := c.Get("user").(*services.JWTClaims)
+			teams := services.NewTeamService()
+
+			members, err := teams.MemberRoles(claims.UserID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check team roles")
+			}
+			if len(members) == 0 {
+				return echo.NewHTTPError(http.StatusForbidden, "No team assigned")
+			}
+
+			requested := c.Param("team_id")
+			if requested == "" {
+				requested = c.Request().Header.Get(TeamHeader)
+			}
+
+			var teamID uint
+			if requested != "" {
+				id, err := strconv.Atoi(requested)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID")
+				}
+				teamID = uint(id)
+			} else {
+				var user models.User
+				if err := database.GetDB().First(&user, claims.UserID).Error; err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "User not found")
+				}
+				var ok bool
+				if teamID, ok = teams.DefaultTeam(&user, members); !ok {
+					return echo.NewHTTPError(http.StatusBadRequest, "You belong to several teams; set the "+TeamHeader+" header")
+				}
+			}
+
+			role := ""
+			for _, m := range members {
+				if m.TeamID == teamID {
+					role = m.Role
+					break
+				}
+			}
+			if role == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "You have no role on this team")
+			}
+			if !services.TeamRoleAtLeast(role, min) {
+				return echo.NewHTTPError(http.StatusForbidden, "Requires the "+min+" role on this team")
+			}
+
+			c.Set("team_id", teamID)
+			c.Set("team_role", role)
+			return next(c)
+		}
+	}
+}
@@ -5,22 +5,143 @@ import (
 )
 
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id" form:"id"`
-	Username  string    `gorm:"unique;not null" json:"username" form:"username"`
-	Password  string    `gorm:"not null" json:"-" form:"password"` // Hashed, but used for binding in login/register? No, specific structs used there.
-	Role      string    `gorm:"type:enum('admin','captain');not null" json:"role" form:"role"`
-	IsActive  bool      `gorm:"default:true" json:"is_active" form:"is_active"`
-	IsBanned  bool      `gorm:"default:false" json:"is_banned" form:"is_banned"`
-	TeamID    *uint     `json:"team_id,omitempty" form:"team_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 uint       `gorm:"primaryKey" json:"id" form:"id"`
+	Username           string     `gorm:"unique;not null" json:"username" form:"username"`
+	Email              *string    `gorm:"size:191;uniqueIndex" json:"email,omitempty" form:"email"` // Needed for password resets
+	Password           string     `gorm:"not null" json:"-" form:"password"`                        // Hashed, but used for binding in login/register? No, specific structs used there.
+	Role               string     `gorm:"type:enum('admin','captain','player');not null" json:"role" form:"role"`
+	IsActive           bool       `gorm:"default:true" json:"is_active" form:"is_active"`
+	IsBanned           bool       `gorm:"default:false" json:"is_banned" form:"is_banned"`
+	BanReason          string     `json:"ban_reason,omitempty"`
+	BanExpiresAt       *time.Time `json:"ban_expires_at,omitempty"`                  // nil for a permanent ban
+	MustChangePassword bool       `gorm:"default:false" json:"must_change_password"` // One-time admin passwords
+	TOTPSecret         string     `gorm:"size:64" json:"-"`                          // Set at enrolment, in use once TOTPEnabled
+	TOTPEnabled        bool       `gorm:"default:false" json:"totp_enabled"`
+	TOTPLastStep       int64      `json:"-"` // Last accepted time step, so a code can't be replayed
+	TeamID             *uint      `json:"team_id,omitempty" form:"team_id"`
+	PlayerID           *uint      `gorm:"uniqueIndex" json:"player_id,omitempty"` // Set for player accounts
+	EmailNotifications string     `gorm:"type:enum('off','instant','digest');default:'off'" json:"email_notifications"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// RefreshToken is a server-side session. Tokens are rotated on every use and
+// all tokens from one login share a FamilyID so reuse can revoke the session.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	FamilyID     string     `gorm:"size:64;index;not null" json:"family_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID *uint      `json:"replaced_by_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// PasswordResetToken is a single-use, time-limited token sent by email
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserIdentity links a user to an account at an OpenID Connect provider
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject     string     `gorm:"size:191;not null;uniqueIndex:idx_provider_subject" json:"-"`
+	Email       string     `json:"email,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState carries a sign-in attempt from the redirect to the provider
+// back to the callback. It is single-use and short-lived.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey"`
+	State        string    `gorm:"size:64;uniqueIndex;not null"`
+	Provider     string    `gorm:"size:50;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:64;not null"`
+	LinkUserID   *uint     // Set when a signed in user links a provider
+	TeamName     string    // Team created if a captain is provisioned
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
+
+// StreamTicket lets a browser open an event stream without putting its access
+// token in the URL. It is single-use and short-lived.
+type StreamTicket struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	UserID    uint      `gorm:"not null"`
+	Role      string    `gorm:"size:20"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// MFARecoveryCode is a single-use code for signing in without the authenticator app
+type MFARecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// LoginAttempt tracks failed logins for a username or client IP when the
+// attempt store is the database (LOGIN_ATTEMPT_STORE=database)
+type LoginAttempt struct {
+	ID            uint   `gorm:"primaryKey"`
+	Identifier    string `gorm:"size:191;uniqueIndex;not null"` // "user:<name>" or "ip:<addr>"
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	UpdatedAt     time.Time `gorm:"index"`
+}
+
+// UsedMFAToken records an "mfa pending" token that was exchanged for a
+// session, so it can't be used again before it expires
+type UsedMFAToken struct {
+	TokenID   string    `gorm:"primaryKey;size:64"` // The token's jti
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// PlayerInvite lets a player claim their Player record with a code handed out by the captain
+type PlayerInvite struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Code        string     `gorm:"size:16;uniqueIndex;not null" json:"code"`
+	PlayerID    uint       `gorm:"not null;index" json:"player_id"`
+	TeamID      uint       `gorm:"not null" json:"team_id"`
+	CreatedByID uint       `json:"created_by_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
+	ClaimedByID *uint      `json:"claimed_by_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// RoleAssignment grants an admin account one of the fine-grained admin roles.
+// A nil TournamentID applies everywhere, otherwise only to that tournament.
+type RoleAssignment struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	Role         string    `gorm:"type:enum('super_admin','tournament_director','scorekeeper','moderator');not null" json:"role"`
+	TournamentID *uint     `gorm:"index" json:"tournament_id,omitempty"`
+	GrantedByID  *uint     `json:"granted_by_id,omitempty"` // nil when granted by bootstrap or backfill
+	CreatedAt    time.Time `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 type Team struct {
 	ID        uint      `gorm:"primaryKey" json:"id" form:"id"`
 	Name      string    `gorm:"unique;not null" json:"name" form:"name"`
 	LogoURL   string    `json:"logo_url" form:"logo_url"`
-	CaptainID uint      `gorm:"unique" json:"captain_id" form:"captain_id"`
+	CaptainID uint      `gorm:"index" json:"captain_id" form:"captain_id"` // Mirrors the team's TeamMember captain
 	Players   []Player  `gorm:"foreignKey:TeamID" json:"players,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -28,13 +149,14 @@ type Team struct {
 
 type Player struct {
 	ID           uint      `gorm:"primaryKey" json:"id" form:"id"`
-	TeamID       uint      `gorm:"not null;index" json:"team_id" form:"team_id"`
+	TeamID       *uint     `gorm:"index" json:"team_id" form:"team_id"` // nil while a free agent
 	Name         string    `gorm:"not null" json:"name" form:"name"`
 	JerseyNumber int       `json:"jersey_number" form:"jersey_number"`
 	Position     string    `json:"position" form:"position"`
 	Age          int       `json:"age" form:"age"`
 	ImageURL     string    `json:"image_url" form:"image_url"`
 	Role         string    `json:"role" form:"role"` // Optional, or redundant with Position? Keeping as requested.
+	Availability string    `gorm:"type:enum('available','injured','unavailable');default:'available'" json:"availability" form:"availability"`
 	GoalsScored  int       `gorm:"default:0" json:"goals_scored" form:"goals_scored"`
 	RedCards     int       `gorm:"default:0" json:"red_cards" form:"red_cards"`
 	IsBanned     bool      `gorm:"default:false" json:"is_banned" form:"is_banned"`
@@ -42,6 +164,30 @@ type Player struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// TeamMember gives a user a management role on a team. Unlike TeamMembership
+// (a player's spell at a team) it is about who may run the team.
+type TeamMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"not null;uniqueIndex:idx_team_user" json:"team_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_team_user;index" json:"user_id"`
+	Role      string    `gorm:"type:enum('captain','vice_captain','manager');not null" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TeamMembership is a player's spell at a team; LeftAt is nil for the current one
+type TeamMembership struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	PlayerID  uint       `gorm:"not null;index" json:"player_id"`
+	TeamID    uint       `gorm:"not null;index" json:"team_id"`
+	TeamName  string     `json:"team_name"` // Copied so history survives team deletion
+	JoinedAt  time.Time  `json:"joined_at"`
+	LeftAt    *time.Time `json:"left_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
 type Staff struct {
 	ID        uint      `gorm:"primaryKey" json:"id" form:"id"`
 	TeamID    uint      `gorm:"not null;index" json:"team_id" form:"team_id"`
@@ -52,8 +198,70 @@ type Staff struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type Season struct {
+	ID          uint         `gorm:"primaryKey" json:"id" form:"id"`
+	Name        string       `gorm:"unique;not null" json:"name" form:"name"`
+	StartDate   *time.Time   `json:"start_date,omitempty" form:"start_date"`
+	EndDate     *time.Time   `json:"end_date,omitempty" form:"end_date"`
+	Status      string       `gorm:"type:enum('upcoming','active','archived');default:'upcoming'" json:"status" form:"status"`
+	ArchivedAt  *time.Time   `json:"archived_at,omitempty"`
+	Tournaments []Tournament `gorm:"foreignKey:SeasonID" json:"tournaments,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// SquadSnapshot freezes a player's team for a season. Names are copied so the
+// history survives later edits or deletion of the player or team.
+type SquadSnapshot struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SeasonID     uint      `gorm:"not null;index" json:"season_id"`
+	TeamID       uint      `gorm:"not null;index" json:"team_id"`
+	TeamName     string    `json:"team_name"`
+	PlayerID     uint      `gorm:"not null;index" json:"player_id"`
+	PlayerName   string    `json:"player_name"`
+	JerseyNumber int       `json:"jersey_number"`
+	Position     string    `json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ArchivedStanding is a frozen copy of a tournament standing at season archive time
+type ArchivedStanding struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	SeasonID       uint   `gorm:"not null;index;uniqueIndex:idx_archived_standing" json:"season_id"`
+	TournamentID   uint   `gorm:"not null;index;uniqueIndex:idx_archived_standing" json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	TeamID         uint   `gorm:"uniqueIndex:idx_archived_standing" json:"team_id"`
+	TeamName       string `json:"team_name"`
+	Rank           int    `json:"rank"`
+	Points         int    `json:"points"`
+	Wins           int    `json:"wins"`
+	Losses         int    `json:"losses"`
+	Draws          int    `json:"draws"`
+	GoalsFor       int    `json:"goals_for"`
+	GoalsAgainst   int    `json:"goals_against"`
+}
+
+// ArchivedPlayerStat is a frozen copy of a player's tournament stats at season archive time
+type ArchivedPlayerStat struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	SeasonID       uint   `gorm:"not null;index;uniqueIndex:idx_archived_player_stat" json:"season_id"`
+	TournamentID   uint   `gorm:"not null;index;uniqueIndex:idx_archived_player_stat" json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	PlayerID       uint   `gorm:"not null;index;uniqueIndex:idx_archived_player_stat" json:"player_id"`
+	PlayerName     string `json:"player_name"`
+	TeamID         uint   `gorm:"uniqueIndex:idx_archived_player_stat" json:"team_id"`
+	TeamName       string `json:"team_name"`
+	Appearances    int    `json:"appearances"`
+	Goals          int    `json:"goals"`
+	Assists        int    `json:"assists"`
+	YellowCards    int    `json:"yellow_cards"`
+	RedCards       int    `json:"red_cards"`
+	CleanSheets    int    `json:"clean_sheets"`
+}
+
 type Tournament struct {
 	ID        uint      `gorm:"primaryKey" json:"id" form:"id"`
+	SeasonID  *uint     `gorm:"index" json:"season_id,omitempty" form:"season_id"`
 	Name      string    `gorm:"not null" json:"name" form:"name"`
 	Status    string    `gorm:"type:enum('registration','active','completed');default:'registration'" json:"status" form:"status"`
 	MaxTeams  int       `gorm:"default:16" json:"max_teams" form:"max_teams"`
@@ -63,16 +271,23 @@ type Tournament struct {
 }
 
 type Match struct {
-	ID           uint   `gorm:"primaryKey" json:"id" form:"id"`
-	TournamentID uint   `gorm:"not null;index" json:"tournament_id" form:"tournament_id"`
-	Round        int    `json:"round" form:"round"`
-	MatchNumber  int    `json:"match_number" form:"match_number"`
-	TeamAID      *uint  `json:"team_a_id" form:"team_a_id"`
-	TeamBID      *uint  `json:"team_b_id" form:"team_b_id"`
-	ScoreA       int    `gorm:"default:0" json:"score_a" form:"score_a"`
-	ScoreB       int    `gorm:"default:0" json:"score_b" form:"score_b"`
-	Status       string `gorm:"type:enum('scheduled','pending_verification','disputed','completed');default:'scheduled'" json:"status" form:"status"`
-	NextMatchID  *uint  `json:"next_match_id" form:"next_match_id"`
+	ID           uint       `gorm:"primaryKey" json:"id" form:"id"`
+	TournamentID uint       `gorm:"not null;index" json:"tournament_id" form:"tournament_id"`
+	Round        int        `json:"round" form:"round"`
+	MatchNumber  int        `json:"match_number" form:"match_number"`
+	TeamAID      *uint      `json:"team_a_id" form:"team_a_id"`
+	TeamBID      *uint      `json:"team_b_id" form:"team_b_id"`
+	ScoreA       int        `gorm:"default:0" json:"score_a" form:"score_a"`
+	ScoreB       int        `gorm:"default:0" json:"score_b" form:"score_b"`
+	Status       string     `gorm:"type:enum('scheduled','live','pending_verification','disputed','completed');default:'scheduled';index" json:"status" form:"status"`
+	NextMatchID  *uint      `json:"next_match_id" form:"next_match_id"`
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty" form:"scheduled_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"` // Kick-off, when the match went live
+	// Set while a captain's reported result awaits the other team
+	ResultSubmittedByTeamID *uint  `json:"result_submitted_by_team_id,omitempty"`
+	DisputeReason           string `json:"dispute_reason,omitempty"`
+	// When availability reminders went out, so they're only sent once
+	ReminderSentAt *time.Time `json:"-"`
 
 	// Relationships
 	TeamA       *Team        `gorm:"foreignKey:TeamAID" json:"team_a,omitempty"`
@@ -84,12 +299,66 @@ type Match struct {
 }
 
 type MatchEvent struct {
+	ID        uint   `gorm:"primaryKey" json:"id" form:"id"`
+	MatchID   uint   `gorm:"not null;index" json:"match_id" form:"match_id"`
+	PlayerID  uint   `gorm:"not null" json:"player_id" form:"player_id"`
+	TeamID    uint   `gorm:"index" json:"team_id"` // Team the player represented when the event happened
+	EventType string `gorm:"type:enum('goal','card_yellow','card_red');not null" json:"event_type" form:"event_type"`
+	Minute    int    `json:"minute" form:"minute"`
+	// AssistPlayerID is only set on goals
+	AssistPlayerID *uint     `gorm:"index" json:"assist_player_id,omitempty" form:"assist_player_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MatchAvailability is a squad member's answer to "can you play this match?"
+type MatchAvailability struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MatchID       uint      `gorm:"not null;uniqueIndex:idx_match_player" json:"match_id"`
+	PlayerID      uint      `gorm:"not null;uniqueIndex:idx_match_player" json:"player_id"`
+	TeamID        uint      `gorm:"not null;index" json:"team_id"`
+	Status        string    `gorm:"type:enum('available','unavailable','maybe');not null" json:"status"`
+	Note          string    `json:"note"`
+	RespondedByID uint      `json:"responded_by_id"` // The player, or the captain answering for them
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MatchAppearance records that a player took part in a match (submitted lineup)
+type MatchAppearance struct {
+	MatchID   uint      `gorm:"primaryKey" json:"match_id"`
+	PlayerID  uint      `gorm:"primaryKey" json:"player_id"`
+	TeamID    uint      `gorm:"not null;index" json:"team_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TransferWindow struct {
 	ID        uint      `gorm:"primaryKey" json:"id" form:"id"`
-	MatchID   uint      `gorm:"not null;index" json:"match_id" form:"match_id"`
-	PlayerID  uint      `gorm:"not null" json:"player_id" form:"player_id"`
-	EventType string    `gorm:"type:enum('goal','card_yellow','card_red');not null" json:"event_type" form:"event_type"`
-	Minute    int       `json:"minute" form:"minute"`
+	Name      string    `gorm:"not null" json:"name" form:"name"`
+	OpensAt   time.Time `gorm:"not null;index" json:"opens_at" form:"opens_at"`
+	ClosesAt  time.Time `gorm:"not null;index" json:"closes_at" form:"closes_at"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Transfer moves a player between teams: the receiving captain requests,
+// the releasing captain agrees, then an admin approves inside a transfer window.
+// Free agents have no releasing captain, so their transfers start out released.
+type Transfer struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	PlayerID      uint       `gorm:"not null;index" json:"player_id"`
+	FromTeamID    *uint      `gorm:"index" json:"from_team_id"` // nil when signing a free agent
+	ToTeamID      uint       `gorm:"not null;index" json:"to_team_id"`
+	Status        string     `gorm:"type:enum('requested','released','approved','rejected','cancelled');default:'requested'" json:"status"`
+	Note          string     `json:"note"`
+	RequestedByID uint       `json:"requested_by_id"`
+	ReleasedByID  *uint      `json:"released_by_id,omitempty"`
+	ReviewedByID  *uint      `json:"reviewed_by_id,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Player *Player `gorm:"foreignKey:PlayerID" json:"player,omitempty"`
 }
 
 type Standing struct {
@@ -106,9 +375,109 @@ type Standing struct {
 }
 
 type Notification struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null;index" json:"user_id"`
-	Message   string    `gorm:"not null" json:"message"`
-	IsRead    bool      `gorm:"default:false" json:"is_read"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Category   string     `gorm:"size:50;default:'general';index" json:"category"` // Domain event name, or general/security/availability
+	Message    string     `gorm:"not null" json:"message"`
+	IsRead     bool       `gorm:"default:false" json:"is_read"`
+	EmailedAt  *time.Time `gorm:"index" json:"emailed_at,omitempty"`  // Sent by email, on its own or in a digest
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at,omitempty"` // Moved out of the inbox by the user
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// DeviceToken is a mobile app install that receives push notifications
+type DeviceToken struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Token      string    `gorm:"size:255;uniqueIndex;not null" json:"token"`
+	Platform   string    `gorm:"type:enum('ios','android','web');not null" json:"platform"`
+	LastSeenAt time.Time `gorm:"index" json:"last_seen_at"` // Refreshed whenever the app registers again
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NotificationPreference turns push off (or back on) for one category of a
+// user's notifications. Categories without a row are pushed.
+type NotificationPreference struct {
+	ID       uint   `gorm:"primaryKey" json:"-"`
+	UserID   uint   `gorm:"not null;uniqueIndex:idx_user_category" json:"-"`
+	Category string `gorm:"size:50;not null;uniqueIndex:idx_user_category" json:"category"`
+	Push     bool   `gorm:"not null" json:"push"`
+}
+
+// PushDelivery is one notification queued for one device, retried with
+// backoff until it is sent or gives up
+type PushDelivery struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID uint      `gorm:"not null;index" json:"notification_id"`
+	DeviceTokenID  uint      `gorm:"not null;index" json:"device_token_id"`
+	Status         string    `gorm:"type:enum('pending','sent','failed');default:'pending';index:idx_push_due" json:"status"`
+	Attempts       int       `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  time.Time `gorm:"index:idx_push_due" json:"next_attempt_at"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// WebhookSubscription posts the domain events it subscribes to, as signed
+// JSON, to an integration's URL
+type WebhookSubscription struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:100;not null" json:"name"`
+	URL          string    `gorm:"size:500;not null" json:"url"`
+	Secret       string    `gorm:"size:128;not null" json:"-"` // Key of the HMAC signature, shown once
+	Events       []string  `gorm:"serializer:json;type:text" json:"events"`
+	TournamentID *uint     `gorm:"index" json:"tournament_id,omitempty"` // Only events of this tournament
+	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	CreatedByID  uint      `json:"created_by_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event posted to one subscription, retried with
+// backoff until the endpoint accepts it or it gives up. Kept as the delivery log.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;index" json:"subscription_id"`
+	Event          string     `gorm:"size:64;not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"type:enum('pending','delivered','failed');default:'pending';index:idx_webhook_due" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_due" json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NotificationBroadcast is an admin message to a group of users, sent by a
+// background job at ScheduledAt
+type NotificationBroadcast struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Message      string     `gorm:"type:text;not null" json:"message"`
+	Category     string     `gorm:"size:50;default:'announcement'" json:"category"`
+	Target       string     `gorm:"type:enum('all','captains','tournament_captains','team_members','role');not null" json:"target"`
+	TournamentID *uint      `json:"tournament_id,omitempty"` // For tournament_captains
+	TeamID       *uint      `json:"team_id,omitempty"`       // For team_members
+	Role         string     `gorm:"size:50" json:"role,omitempty"`
+	ScheduledAt  time.Time  `gorm:"index" json:"scheduled_at"`
+	Status       string     `gorm:"type:enum('scheduled','sending','sent','failed','cancelled');default:'scheduled';index" json:"status"`
+	Recipients   int        `json:"recipients"`
+	Delivered    int        `json:"delivered"`
+	LastUserID   uint       `json:"-"` // Recipients are sent in user ID order; an interrupted send resumes after this one
+	Error        string     `json:"error,omitempty"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`
+	CreatedByID  uint       `json:"created_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// NotificationTemplate overrides the built-in message of a domain event.
+// Placeholders like {team_a} are filled in from the event.
+type NotificationTemplate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Event       string    `gorm:"size:64;uniqueIndex;not null" json:"event"`
+	Body        string    `gorm:"type:text;not null" json:"body"`
+	UpdatedByID *uint     `json:"updated_by_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
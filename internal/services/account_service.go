@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountInactive    = errors.New("account is deactivated")
+	ErrAccountBanned      = errors.New("account is banned")
+)
+
+// BanError carries the details shown to a banned user. It matches ErrAccountBanned with errors.Is.
+type BanError struct {
+	Reason    string
+	ExpiresAt *time.Time
+}
+
+func (e *BanError) Error() string {
+	if e.ExpiresAt != nil {
+		return fmt.Sprintf("account is banned until %s", e.ExpiresAt.Format(time.RFC3339))
+	}
+	return "account is banned"
+}
+
+func (e *BanError) Is(target error) bool {
+	return target == ErrAccountBanned
+}
+
+// CheckAccount returns an error if the user may not sign in or use their token.
+// Expired bans are lifted on the spot.
+func CheckAccount(db *gorm.DB, user *models.User) error {
+	if user.IsBanned {
+		if user.BanExpiresAt != nil && time.Now().After(*user.BanExpiresAt) {
+			user.IsBanned = false
+			user.BanReason = ""
+			user.BanExpiresAt = nil
+			if err := db.Model(user).Select("is_banned", "ban_reason", "ban_expires_at").Updates(user).Error; err != nil {
+				return err
+			}
+		} else {
+			return &BanError{Reason: user.BanReason, ExpiresAt: user.BanExpiresAt}
+		}
+	}
+	if !user.IsActive {
+		return ErrAccountInactive
+	}
+	return nil
+}
+
+// AccountStatusCache remembers recent account checks so the auth middleware
+// doesn't hit the database on every request. Admin actions invalidate entries
+// so bans and deactivations apply immediately.
+type AccountStatusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uint]accountStatusEntry
+}
+
+type accountStatusEntry struct {
+	err       error
+	checkedAt time.Time
+}
+
+var (
+	accountCache     *AccountStatusCache
+	accountCacheOnce sync.Once
+)
+
+// Accounts returns the shared account status cache. It is built on first use,
+// after the environment has been loaded.
+func Accounts() *AccountStatusCache {
+	accountCacheOnce.Do(func() {
+		accountCache = &AccountStatusCache{
+			ttl:     utils.EnvDuration("ACCOUNT_STATUS_CACHE_TTL", 30*time.Second),
+			entries: make(map[uint]accountStatusEntry),
+		}
+	})
+	return accountCache
+}
+
+// Check returns the cached account status, loading it when missing or stale.
+// A deleted user is reported as inactive.
+func (c *AccountStatusCache) Check(userID uint) error {
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && time.Since(entry.checkedAt) < c.ttl {
+		return entry.err
+	}
+
+	db := database.GetDB()
+	var user models.User
+	var status error
+	if err := db.First(&user, userID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		status = ErrAccountInactive
+	} else if err != nil {
+		return err
+	} else {
+		status = CheckAccount(db, &user)
+	}
+
+	// A timed ban must not outlive its expiry in the cache
+	checkedAt := time.Now()
+	var ban *BanError
+	if errors.As(status, &ban) && ban.ExpiresAt != nil && ban.ExpiresAt.Before(checkedAt.Add(c.ttl)) {
+		checkedAt = ban.ExpiresAt.Add(-c.ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[userID] = accountStatusEntry{err: status, checkedAt: checkedAt}
+	c.mu.Unlock()
+	return status
+}
+
+// Invalidate drops the cached status of a user after their account changed
+func (c *AccountStatusCache) Invalidate(userID uint) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
@@ -2,35 +2,63 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/yourname/leaguemaster/internal/models"
 	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/mailer"
 	"github.com/yourname/leaguemaster/pkg/utils"
 	"gorm.io/gorm"
 )
 
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
-	TeamID *uint  `json:"team_id,omitempty"`
+	UserID   uint   `json:"user_id"`
+	Role     string `json:"role"`
+	PlayerID *uint  `json:"player_id,omitempty"`
+	// Set until a bootstrapped admin replaces their one-time password
+	MustChangePassword bool `json:"pwd_change,omitempty"`
+	// Set on the short-lived token that only allows completing two-factor login
+	MFAPending bool `json:"mfa_pending,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type AuthService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	mailer mailer.Sender
 }
 
 func NewAuthService() *AuthService {
 	return &AuthService{
-		db: database.GetDB(),
+		db:     database.GetDB(),
+		mailer: mailer.NewFromEnv(),
 	}
 }
 
-func (s *AuthService) RegisterCaptain(username, password, teamName string) (*models.User, error) {
+// ErrInvalidEmail is returned for an email address that isn't one plain address
+var ErrInvalidEmail = errors.New("email must be a plain address such as name@example.com")
+
+// CheckEmail validates an optional email address before it is saved on an
+// account. Nil or empty means no address.
+func CheckEmail(email *string) error {
+	if email == nil || *email == "" {
+		return nil
+	}
+	if mailer.CheckAddress(*email) != nil {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+func (s *AuthService) RegisterCaptain(username, password, teamName string, email *string) (*models.User, error) {
+	if err := CheckEmail(email); err != nil {
+		return nil, err
+	}
+
 	// Transaction to create User and Team
 	tx := s.db.Begin()
 
@@ -43,6 +71,7 @@ func (s *AuthService) RegisterCaptain(username, password, teamName string) (*mod
 	user := models.User{
 		Username: username,
 		Password: hashedPassword,
+		Email:    email,
 		Role:     "captain",
 		IsActive: true,
 	}
@@ -62,6 +91,12 @@ func (s *AuthService) RegisterCaptain(username, password, teamName string) (*mod
 		return nil, err
 	}
 
+	member := models.TeamMember{TeamID: team.ID, UserID: user.ID, Role: TeamRoleCaptain}
+	if err := tx.Create(&member).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Update user with TeamID
 	user.TeamID = &team.ID
 	if err := tx.Save(&user).Error; err != nil {
@@ -73,69 +108,576 @@ func (s *AuthService) RegisterCaptain(username, password, teamName string) (*mod
 	return &user, nil
 }
 
-func (s *AuthService) Login(username, password string) (string, *models.User, error) {
+// ClaimPlayerInvite creates a player account linked to the invited Player record
+func (s *AuthService) ClaimPlayerInvite(code, username, password string) (*models.User, error) {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var invite models.PlayerInvite
+		if err := tx.Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&invite).Error; err != nil {
+			return errors.New("invalid invite code")
+		}
+		if invite.ClaimedAt != nil || time.Now().After(invite.ExpiresAt) {
+			return errors.New("invite code has expired")
+		}
+
+		var player models.Player
+		if err := tx.First(&player, invite.PlayerID).Error; err != nil {
+			return errors.New("invalid invite code")
+		}
+		// The captain who invited them must still be their captain
+		if player.TeamID == nil || *player.TeamID != invite.TeamID {
+			return errors.New("invite code has expired")
+		}
+
+		var linked int64
+		if err := tx.Model(&models.User{}).Where("player_id = ?", player.ID).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return errors.New("player already has an account")
+		}
+
+		hashedPassword, err := utils.HashPassword(password)
+		if err != nil {
+			return err
+		}
+
+		user = models.User{
+			Username: username,
+			Password: hashedPassword,
+			Role:     "player",
+			IsActive: true,
+			PlayerID: &player.ID,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		invite.ClaimedAt = &now
+		invite.ClaimedByID = &user.ID
+		return tx.Save(&invite).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LoginResult is either a session or, when a second factor is needed, an MFA challenge
+type LoginResult struct {
+	Tokens *TokenPair
+	User   *models.User
+	MFA    *MFAChallenge
+}
+
+// MFAChallenge carries the "mfa pending" token that is exchanged for a
+// session once the user passes the second factor (or enrols, for admins
+// without 2FA yet).
+type MFAChallenge struct {
+	Token              string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+}
+
+// Login checks a password. Failures are counted per username and client IP,
+// and a throttled or locked out attempt is refused before the password is checked.
+func (s *AuthService) Login(username, password, ip string) (*LoginResult, error) {
+	guard := LoginAttempts()
+	if err := guard.Check(username, ip); err != nil {
+		log.Printf("Login throttled for user '%s' from %s: %v", username, ip, err)
+		return nil, err
+	}
+
 	var user models.User
 	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
 		log.Printf("Login failed: User '%s' not found", username)
-		return "", nil, errors.New("invalid credentials")
+		guard.Fail(username, ip)
+		return nil, ErrInvalidCredentials
 	}
 
 	if !utils.CheckPasswordHash(password, user.Password) {
 		log.Printf("Login failed: Password mismatch for user '%s'", username)
-		return "", nil, errors.New("invalid credentials")
+		if guard.Fail(username, ip) {
+			s.notifyLockout(&user)
+		}
+		return nil, ErrInvalidCredentials
+	}
+	guard.Succeed(username)
+
+	// Only reveal the account state once the password is known to be right
+	if err := CheckAccount(s.db, &user); err != nil {
+		log.Printf("Login refused for user '%s': %v", username, err)
+		return nil, err
+	}
+
+	return s.completeLogin(s.db, &user)
+}
+
+// notifyLockout tells the account owner their login was locked by failed attempts
+func (s *AuthService) notifyLockout(user *models.User) {
+	message := fmt.Sprintf("Your account was locked for %s after too many failed login attempts. If this wasn't you, consider changing your password.",
+		LoginAttempts().LockoutDuration())
+	if _, err := NewNotificationService().Send(user.ID, "security", message); err != nil {
+		log.Printf("Failed to notify user %d of lockout: %v", user.ID, err)
+	}
+}
+
+// mfaRequired reports whether the user needs a second factor to sign in.
+// It is mandatory for admins and opt-in for everyone else.
+func mfaRequired(user *models.User) bool {
+	return user.TOTPEnabled || user.Role == "admin"
+}
+
+func mfaPendingTTL() time.Duration {
+	return utils.EnvDuration("MFA_PENDING_TTL", 5*time.Minute)
+}
+
+// completeLogin finishes every sign-in once the first factor checked out:
+// it issues a session, or an MFA challenge when a second factor is needed.
+func (s *AuthService) completeLogin(tx *gorm.DB, user *models.User) (*LoginResult, error) {
+	if mfaRequired(user) {
+		token, err := generateMFAToken(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{User: user, MFA: &MFAChallenge{
+			Token:              token,
+			ExpiresIn:          int(mfaPendingTTL().Seconds()),
+			EnrollmentRequired: !user.TOTPEnabled,
+		}}, nil
+	}
+
+	tokens, err := s.issueTokens(tx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens, User: user}, nil
+}
+
+// generateMFAToken signs the "mfa pending" token, which AuthMiddleware refuses
+func generateMFAToken(user *models.User) (string, error) {
+	jti, err := utils.RandomToken(16)
+	if err != nil {
+		return "", err
 	}
 
-	// Generate Token
 	claims := JWTClaims{
-		UserID: user.ID,
-		Role:   user.Role,
-		TeamID: user.TeamID,
+		UserID:     user.ID,
+		Role:       user.Role,
+		MFAPending: true,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(72 * time.Hour)),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaPendingTTL())),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// Sessions
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+}
+
+func accessTokenTTL() time.Duration {
+	return utils.EnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+func refreshTokenTTL() time.Duration {
+	return utils.EnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// generateAccessToken signs a short-lived JWT for the user
+func generateAccessToken(user *models.User) (string, error) {
+	jti, err := utils.RandomToken(16)
 	if err != nil {
-		return "", nil, err
+		return "", err
 	}
 
-	return tokenString, &user, nil
+	claims := JWTClaims{
+		UserID:             user.ID,
+		Role:               user.Role,
+		PlayerID:           user.PlayerID,
+		MustChangePassword: user.MustChangePassword,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL())),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 }
 
-func (s *AuthService) CreateAdmin(username, password string) error {
+// issueTokens creates an access token and a refresh token. An empty familyID starts a new session.
+func (s *AuthService) issueTokens(tx *gorm.DB, user *models.User, familyID string) (*TokenPair, error) {
+	accessToken, err := generateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if familyID == "" {
+		if familyID, err = utils.RandomToken(16); err != nil {
+			return nil, err
+		}
+	}
+
+	record := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL()),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenTTL().Seconds()),
+	}, nil
+}
+
+// Refresh rotates a refresh token. Presenting an already rotated token means it
+// was stolen (or replayed), so the whole session family is revoked.
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, *models.User, error) {
+	var tokens *TokenPair
+	var user models.User
+	var record models.RefreshToken
+	reused := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&record).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if record.RevokedAt != nil {
+			reused = true
+			return nil
+		}
+		if time.Now().After(record.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := tx.First(&user, record.UserID).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if err := CheckAccount(tx, &user); err != nil {
+			return err
+		}
+		// Sessions from before 2FA became mandatory have to sign in again
+		if user.Role == "admin" && !user.TOTPEnabled {
+			return ErrInvalidRefreshToken
+		}
+
+		// Revoke it only if still live: of two concurrent refreshes with the
+		// same token, one wins and the other is treated as reuse
+		claim := tx.Model(&models.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", record.ID).Update("revoked_at", time.Now())
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+
+		var err error
+		tokens, err = s.issueTokens(tx, &user, record.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		var replacement models.RefreshToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(tokens.RefreshToken)).First(&replacement).Error; err != nil {
+			return err
+		}
+		return tx.Model(&record).Update("replaced_by_id", replacement.ID).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if reused {
+		log.Printf("Refresh token reuse detected for user %d, revoking session %s", record.UserID, record.FamilyID)
+		if err := s.revokeFamily(record.FamilyID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	return tokens, &user, nil
+}
+
+// Logout revokes the session the refresh token belongs to
+func (s *AuthService) Logout(refreshToken string) error {
+	var record models.RefreshToken
+	if err := s.db.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&record).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return s.revokeFamily(record.FamilyID)
+}
+
+// RevokeAllSessions signs a user out everywhere (password change, ban, ...)
+func (s *AuthService) RevokeAllSessions(userID uint) error {
+	return s.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *AuthService) revokeFamily(familyID string) error {
+	return s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Stream tickets
+
+var ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+
+// StreamTicketTTL is how long a stream ticket can be redeemed
+func StreamTicketTTL() time.Duration {
+	return utils.EnvDuration("STREAM_TICKET_TTL", 30*time.Second)
+}
+
+// IssueStreamTicket returns a single-use ticket that opens an event stream as
+// the signed in user, for clients that can't send the access token in a header
+func (s *AuthService) IssueStreamTicket(claims *JWTClaims) (string, error) {
+	ticket, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// Drop unused tickets while we're here
+	s.db.Where("expires_at < ?", time.Now()).Delete(&models.StreamTicket{})
+
+	record := models.StreamTicket{
+		TokenHash: utils.HashToken(ticket),
+		UserID:    claims.UserID,
+		Role:      claims.Role,
+		ExpiresAt: time.Now().Add(StreamTicketTTL()),
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// RedeemStreamTicket consumes a ticket and returns the claims it was issued for
+func (s *AuthService) RedeemStreamTicket(ticket string) (*JWTClaims, error) {
+	var record models.StreamTicket
+	if err := s.db.Where("token_hash = ?", utils.HashToken(ticket)).First(&record).Error; err != nil {
+		return nil, ErrInvalidStreamTicket
+	}
+	// Of two streams racing with the ticket, only the one that deletes it opens
+	claim := s.db.Where("id = ? AND expires_at > ?", record.ID, time.Now()).Delete(&models.StreamTicket{})
+	if claim.Error != nil {
+		return nil, claim.Error
+	}
+	if claim.RowsAffected != 1 {
+		return nil, ErrInvalidStreamTicket
+	}
+	return &JWTClaims{UserID: record.UserID, Role: record.Role}, nil
+}
+
+// Passwords
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+func passwordResetTTL() time.Duration {
+	return utils.EnvDuration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// setPassword stores a new password and signs the user out everywhere
+func (s *AuthService) setPassword(tx *gorm.DB, user *models.User, password string) error {
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
 		return err
 	}
+	if err := tx.Model(user).Updates(map[string]interface{}{"password": hashedPassword, "must_change_password": false}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", time.Now()).Error
+}
 
+// ChangePassword checks the current password, then replaces it. Every session is
+// revoked and a fresh token pair is returned so the caller stays signed in.
+func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword string) (*TokenPair, *models.User, error) {
 	var user models.User
-	result := s.db.Where("role = ?", "admin").First(&user)
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return nil, nil, ErrInvalidCredentials
+	}
 
-	if result.Error == gorm.ErrRecordNotFound {
-		// Create
-		admin := models.User{
-			Username: username,
-			Password: hashedPassword,
-			Role:     "admin",
-			IsActive: true,
+	var tokens *TokenPair
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.setPassword(tx, &user, newPassword); err != nil {
+			return err
 		}
-		if err := s.db.Create(&admin).Error; err != nil {
+		var err error
+		tokens, err = s.issueTokens(tx, &user, "")
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, &user, nil
+}
+
+// RequestPasswordReset emails a reset link to the account matching the username
+// or email. It never reports whether an account exists.
+func (s *AuthService) RequestPasswordReset(identifier string) error {
+	var user models.User
+	if err := s.db.Where("username = ? OR email = ?", identifier, identifier).First(&user).Error; err != nil {
+		log.Printf("Password reset requested for unknown account '%s'", identifier)
+		return nil
+	}
+	if user.Email == nil || *user.Email == "" {
+		log.Printf("Password reset requested for user '%s' without an email address", user.Username)
+		return nil
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only the latest link works
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
 			return err
 		}
-		log.Printf("Admin user '%s' created successfully", username)
-	} else if result.Error == nil {
-		// Update password to ensure known state
-		user.Password = hashedPassword
-		user.Username = username // Ensure username matches (though role is unique admin? No, role is just enum)
-		if err := s.db.Save(&user).Error; err != nil {
+		record := models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(passwordResetTTL()),
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv("APP_BASE_URL"), token)
+	return s.mailer.Send(mailer.Message{
+		To:      *user.Email,
+		Subject: "Reset your LeagueMaster password",
+		Text: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset your password. Use the link below within %s:\n\n%s\n\nIf it wasn't you, you can ignore this email.\n",
+			user.Username, passwordResetTTL(), link),
+	})
+}
+
+// ResetPassword consumes a reset token and sets the new password
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var record models.PasswordResetToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(token)).First(&record).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+		if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
+
+		var user models.User
+		if err := tx.First(&user, record.UserID).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+
+		// Claim the token, so of two requests racing with it only one sets a password
+		claim := tx.Model(&models.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", record.ID).Update("used_at", time.Now())
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+		return s.setPassword(tx, &user, newPassword)
+	})
+}
+
+// Admins
+
+// CreateAdmin adds an admin account, with the league-wide role given unless
+// it is empty. The account and its role are saved together, so a failure
+// leaves neither. The password is a one-time password: the admin has to
+// change it on first login.
+func (s *AuthService) CreateAdmin(username, password string, email *string, role string, grantedByID *uint) (*models.User, error) {
+	if err := CheckEmail(email); err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("username '%s' is already taken", username)
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := models.User{
+		Username:           username,
+		Email:              email,
+		Password:           hashedPassword,
+		Role:               "admin",
+		IsActive:           true,
+		MustChangePassword: true,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&admin).Error; err != nil {
 			return err
 		}
-		log.Printf("Admin user '%s' updated with default password", username)
-	} else {
-		return result.Error
+		if role == "" {
+			return nil
+		}
+		_, err := assignRole(tx, admin.ID, role, nil, grantedByID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("Admin user '%s' created", username)
+	return &admin, nil
+}
 
-	return nil
+// BootstrapAdmin creates the first admin, but only while no admin exists.
+// Existing accounts are never touched.
+func (s *AuthService) BootstrapAdmin(username, password string) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", "admin").Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := s.CreateAdmin(username, password, nil, RoleSuperAdmin, nil); err != nil {
+		return false, err
+	}
+	return true, nil
 }
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AvailabilityService struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityService() *AvailabilityService {
+	return &AvailabilityService{
+		db: database.GetDB(),
+	}
+}
+
+type PlayerAvailability struct {
+	PlayerID   uint   `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	Status     string `json:"status"` // available, unavailable, maybe or no_response
+	Note       string `json:"note,omitempty"`
+}
+
+type AvailabilitySummary struct {
+	MatchID     uint                 `json:"match_id"`
+	TeamID      uint                 `json:"team_id"`
+	ScheduledAt *time.Time           `json:"scheduled_at,omitempty"`
+	Available   int                  `json:"available"`
+	Unavailable int                  `json:"unavailable"`
+	Maybe       int                  `json:"maybe"`
+	NoResponse  int                  `json:"no_response"`
+	Players     []PlayerAvailability `json:"players"`
+}
+
+// SetAvailability records (or changes) a player's answer for a match
+func (s *AvailabilityService) SetAvailability(matchID, playerID uint, status, note string, respondedByID uint) (*models.MatchAvailability, error) {
+	switch status {
+	case "available", "unavailable", "maybe":
+	default:
+		return nil, errors.New("status must be available, unavailable or maybe")
+	}
+
+	var match models.Match
+	if err := s.db.First(&match, matchID).Error; err != nil {
+		return nil, err
+	}
+	if match.Status == "completed" {
+		return nil, errors.New("match is already completed")
+	}
+
+	var player models.Player
+	if err := s.db.First(&player, playerID).Error; err != nil {
+		return nil, err
+	}
+	if !onTeam(player.TeamID, match.TeamAID) && !onTeam(player.TeamID, match.TeamBID) {
+		return nil, errors.New("player not playing in this match")
+	}
+
+	availability := models.MatchAvailability{
+		MatchID:       matchID,
+		PlayerID:      playerID,
+		TeamID:        *player.TeamID,
+		Status:        status,
+		Note:          note,
+		RespondedByID: respondedByID,
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "match_id"}, {Name: "player_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"team_id", "status", "note", "responded_by_id", "updated_at"}),
+	}).Create(&availability).Error
+	if err != nil {
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// Summary lists every squad member of the team with their answer for the match
+func (s *AvailabilityService) Summary(matchID, teamID uint) (*AvailabilitySummary, error) {
+	var match models.Match
+	if err := s.db.First(&match, matchID).Error; err != nil {
+		return nil, err
+	}
+	if !onTeam(&teamID, match.TeamAID) && !onTeam(&teamID, match.TeamBID) {
+		return nil, errors.New("team not playing in this match")
+	}
+
+	var players []models.Player
+	if err := s.db.Where("team_id = ?", teamID).Order("name").Find(&players).Error; err != nil {
+		return nil, err
+	}
+
+	var answers []models.MatchAvailability
+	if err := s.db.Where("match_id = ? AND team_id = ?", matchID, teamID).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	byPlayer := make(map[uint]models.MatchAvailability)
+	for _, a := range answers {
+		byPlayer[a.PlayerID] = a
+	}
+
+	summary := &AvailabilitySummary{MatchID: match.ID, TeamID: teamID, ScheduledAt: match.ScheduledAt, Players: []PlayerAvailability{}}
+	for _, p := range players {
+		entry := PlayerAvailability{PlayerID: p.ID, PlayerName: p.Name, Status: "no_response"}
+		if a, ok := byPlayer[p.ID]; ok {
+			entry.Status = a.Status
+			entry.Note = a.Note
+		}
+
+		switch entry.Status {
+		case "available":
+			summary.Available++
+		case "unavailable":
+			summary.Unavailable++
+		case "maybe":
+			summary.Maybe++
+		default:
+			summary.NoResponse++
+		}
+		summary.Players = append(summary.Players, entry)
+	}
+
+	return summary, nil
+}
+
+// UpcomingSummaries returns a summary for each of the team's matches that are still to be played
+func (s *AvailabilityService) UpcomingSummaries(teamID uint) ([]AvailabilitySummary, error) {
+	var matches []models.Match
+	if err := s.db.Where("(team_a_id = ? OR team_b_id = ?) AND status = ?", teamID, teamID, "scheduled").
+		Order("scheduled_at IS NULL, scheduled_at, id").
+		Find(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	summaries := make([]AvailabilitySummary, 0, len(matches))
+	for _, m := range matches {
+		summary, err := s.Summary(m.ID, teamID)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, *summary)
+	}
+	return summaries, nil
+}
+
+// SendReminders notifies players with an account who haven't answered for
+// matches kicking off within the lead time. Each match is reminded once.
+func (s *AvailabilityService) SendReminders(now time.Time, lead time.Duration) (int, error) {
+	var matches []models.Match
+	if err := s.db.Where("status = ? AND reminder_sent_at IS NULL AND scheduled_at BETWEEN ? AND ?", "scheduled", now, now.Add(lead)).
+		Find(&matches).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, m := range matches {
+		var teamIDs []uint
+		for _, id := range []*uint{m.TeamAID, m.TeamBID} {
+			if id != nil {
+				teamIDs = append(teamIDs, *id)
+			}
+		}
+		if len(teamIDs) == 0 {
+			continue
+		}
+
+		// Player accounts on either team with no answer for this match
+		var users []models.User
+		if err := s.db.Joins("JOIN players ON players.id = users.player_id").
+			Where("players.team_id IN ?", teamIDs).
+			Where("users.player_id NOT IN (?)", s.db.Model(&models.MatchAvailability{}).Select("player_id").Where("match_id = ?", m.ID)).
+			Find(&users).Error; err != nil {
+			return sent, err
+		}
+
+		var created []models.Notification
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			for _, u := range users {
+				notification := models.Notification{
+					UserID:   u.ID,
+					Category: "availability",
+					Message:  fmt.Sprintf("Are you available for match #%d on %s? Let your captain know.", m.ID, m.ScheduledAt.Format("Mon 2 Jan 15:04")),
+				}
+				if err := tx.Create(&notification).Error; err != nil {
+					return err
+				}
+				created = append(created, notification)
+			}
+			return tx.Model(&models.Match{}).Where("id = ?", m.ID).Update("reminder_sent_at", now).Error
+		})
+		if err != nil {
+			return sent, err
+		}
+		PublishNotifications(created...)
+		sent += len(users)
+	}
+
+	return sent, nil
+}
+
+// RunReminders checks for due reminders every interval. Meant to run in its own goroutine.
+func (s *AvailabilityService) RunReminders(interval, lead time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := s.SendReminders(time.Now(), lead); err != nil {
+			log.Printf("Availability reminders failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d availability reminders", sent)
+		}
+		<-ticker.C
+	}
+}
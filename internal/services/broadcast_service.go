@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Broadcast targets
+const (
+	TargetAll                = "all"
+	TargetCaptains           = "captains"
+	TargetTournamentCaptains = "tournament_captains"
+	TargetTeamMembers        = "team_members"
+	TargetRole               = "role"
+)
+
+var (
+	ErrInvalidBroadcast   = errors.New("invalid broadcast")
+	ErrBroadcastStarted   = errors.New("broadcast has already been sent")
+	ErrBroadcastNotFailed = errors.New("only a failed broadcast can be resumed")
+)
+
+// broadcastBatch is how many notifications are written per transaction
+const broadcastBatch = 500
+
+// broadcastLease is how long a broadcast may go without progress while
+// sending before it is taken for abandoned (the sender died) and resumed
+func broadcastLease() time.Duration {
+	return utils.EnvDuration("BROADCAST_LEASE", 5*time.Minute)
+}
+
+// broadcastWake lets a new broadcast skip the wait for the next scheduler tick
+var broadcastWake = make(chan struct{}, 1)
+
+type BroadcastService struct {
+	db *gorm.DB
+}
+
+func NewBroadcastService() *BroadcastService {
+	return &BroadcastService{
+		db: database.GetDB(),
+	}
+}
+
+// BroadcastRequest describes who gets a broadcast and when
+type BroadcastRequest struct {
+	Message      string     `json:"message" form:"message"`
+	Category     string     `json:"category" form:"category"`
+	Target       string     `json:"target" form:"target"`
+	TournamentID *uint      `json:"tournament_id" form:"tournament_id"`
+	TeamID       *uint      `json:"team_id" form:"team_id"`
+	Role         string     `json:"role" form:"role"`                 // A user role (admin, captain, player) or an admin role
+	ScheduledAt  *time.Time `json:"scheduled_at" form:"scheduled_at"` // Empty to send now
+}
+
+// Create validates and queues a broadcast for the background job
+func (s *BroadcastService) Create(req BroadcastRequest, adminID uint) (*models.NotificationBroadcast, error) {
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		return nil, fmt.Errorf("%w: message is required", ErrInvalidBroadcast)
+	}
+
+	broadcast := models.NotificationBroadcast{
+		Message:     req.Message,
+		Category:    req.Category,
+		Target:      req.Target,
+		ScheduledAt: time.Now(),
+		Status:      "scheduled",
+		CreatedByID: adminID,
+	}
+	if broadcast.Category == "" {
+		broadcast.Category = "announcement"
+	}
+	if !slices.Contains(NotificationCategories, broadcast.Category) {
+		return nil, fmt.Errorf("%w: unknown category '%s'", ErrInvalidBroadcast, broadcast.Category)
+	}
+	if req.ScheduledAt != nil {
+		broadcast.ScheduledAt = *req.ScheduledAt
+	}
+
+	switch req.Target {
+	case TargetAll, TargetCaptains:
+	case TargetTournamentCaptains:
+		if req.TournamentID == nil {
+			return nil, fmt.Errorf("%w: tournament_id is required", ErrInvalidBroadcast)
+		}
+		if err := s.db.First(&models.Tournament{}, *req.TournamentID).Error; err != nil {
+			return nil, err
+		}
+		broadcast.TournamentID = req.TournamentID
+	case TargetTeamMembers:
+		if req.TeamID == nil {
+			return nil, fmt.Errorf("%w: team_id is required", ErrInvalidBroadcast)
+		}
+		if err := s.db.First(&models.Team{}, *req.TeamID).Error; err != nil {
+			return nil, err
+		}
+		broadcast.TeamID = req.TeamID
+	case TargetRole:
+		if _, adminRole := RolePermissions[req.Role]; !adminRole && req.Role != "admin" && req.Role != "captain" && req.Role != "player" {
+			return nil, fmt.Errorf("%w: unknown role '%s'", ErrInvalidBroadcast, req.Role)
+		}
+		broadcast.Role = req.Role
+	default:
+		return nil, fmt.Errorf("%w: target must be one of all, captains, tournament_captains, team_members, role", ErrInvalidBroadcast)
+	}
+
+	if err := s.db.Create(&broadcast).Error; err != nil {
+		return nil, err
+	}
+
+	select {
+	case broadcastWake <- struct{}{}:
+	default:
+	}
+	return &broadcast, nil
+}
+
+// List returns the broadcasts, newest first
+func (s *BroadcastService) List() ([]models.NotificationBroadcast, error) {
+	var broadcasts []models.NotificationBroadcast
+	if err := s.db.Order("scheduled_at desc").Find(&broadcasts).Error; err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+func (s *BroadcastService) Get(id uint) (*models.NotificationBroadcast, error) {
+	var broadcast models.NotificationBroadcast
+	if err := s.db.First(&broadcast, id).Error; err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+// Cancel stops a broadcast that hasn't started sending
+func (s *BroadcastService) Cancel(id uint) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	result := s.db.Model(&models.NotificationBroadcast{}).Where("id = ? AND status = ?", id, "scheduled").Update("status", "cancelled")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBroadcastStarted
+	}
+	return nil
+}
+
+// Resume sends a failed broadcast again, to the recipients it didn't reach
+func (s *BroadcastService) Resume(id uint) (*models.NotificationBroadcast, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	result := s.db.Model(&models.NotificationBroadcast{}).Where("id = ? AND status = ?", id, "failed").
+		Updates(map[string]interface{}{"status": "scheduled", "error": ""})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrBroadcastNotFailed
+	}
+
+	select {
+	case broadcastWake <- struct{}{}:
+	default:
+	}
+	return s.Get(id)
+}
+
+// recipients resolves a broadcast's target to user IDs
+func (s *BroadcastService) recipients(b *models.NotificationBroadcast) ([]uint, error) {
+	var ids []uint
+	var err error
+	switch b.Target {
+	case TargetAll:
+		err = s.db.Model(&models.User{}).Where("is_active = ?", true).Pluck("id", &ids).Error
+	case TargetCaptains:
+		err = s.db.Model(&models.TeamMember{}).Where("role = ?", TeamRoleCaptain).Pluck("user_id", &ids).Error
+	case TargetTournamentCaptains:
+		err = s.db.Model(&models.TeamMember{}).
+			Where("role = ? AND team_id IN (?)", TeamRoleCaptain,
+				s.db.Model(&models.Standing{}).Select("team_id").Where("tournament_id = ?", *b.TournamentID)).
+			Pluck("user_id", &ids).Error
+	case TargetTeamMembers:
+		var managers, players []uint
+		if managers, err = teamManagerIDs(s.db, []uint{*b.TeamID}); err != nil {
+			return nil, err
+		}
+		if players, err = teamPlayerUserIDs(s.db, []uint{*b.TeamID}); err != nil {
+			return nil, err
+		}
+		ids = append(managers, players...)
+	case TargetRole:
+		if _, adminRole := RolePermissions[b.Role]; adminRole {
+			err = s.db.Model(&models.RoleAssignment{}).Where("role = ?", b.Role).Pluck("user_id", &ids).Error
+		} else {
+			err = s.db.Model(&models.User{}).Where("role = ? AND is_active = ?", b.Role, true).Pluck("id", &ids).Error
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uniqueIDs(ids), nil
+}
+
+// SendDue sends every broadcast whose time has come and returns how many it
+// handled. Broadcasts left sending by an instance that died are picked up again.
+func (s *BroadcastService) SendDue(now time.Time) (int, error) {
+	if err := s.db.Model(&models.NotificationBroadcast{}).
+		Where("status = ? AND updated_at < ?", "sending", now.Add(-broadcastLease())).
+		Update("status", "scheduled").Error; err != nil {
+		return 0, err
+	}
+
+	var due []models.NotificationBroadcast
+	if err := s.db.Where("status = ? AND scheduled_at <= ?", "scheduled", now).Order("scheduled_at").Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for i := range due {
+		// Claim it, so another instance running the job doesn't send it too
+		claim := s.db.Model(&models.NotificationBroadcast{}).Where("id = ? AND status = ?", due[i].ID, "scheduled").Update("status", "sending")
+		if claim.Error != nil {
+			return handled, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		s.send(&due[i])
+		handled++
+	}
+	return handled, nil
+}
+
+// send delivers a claimed broadcast in batches, recording the counts as it
+// goes. It starts after the last recipient an earlier run reached.
+func (s *BroadcastService) send(b *models.NotificationBroadcast) {
+	fail := func(err error) {
+		log.Printf("Broadcast %d failed: %v", b.ID, err)
+		s.db.Model(b).Updates(map[string]interface{}{"status": "failed", "error": err.Error()})
+	}
+
+	userIDs, err := s.recipients(b)
+	if err != nil {
+		fail(err)
+		return
+	}
+	slices.Sort(userIDs)
+	pending := userIDs[remainingFrom(userIDs, b.LastUserID):]
+	if err := s.db.Model(b).Update("recipients", b.Delivered+len(pending)).Error; err != nil {
+		fail(err)
+		return
+	}
+
+	for start := 0; start < len(pending); start += broadcastBatch {
+		batch := pending[start:min(start+broadcastBatch, len(pending))]
+		sent := make([]models.Notification, len(batch))
+		for i, id := range batch {
+			sent[i] = models.Notification{UserID: id, Category: b.Category, Message: b.Message}
+		}
+		// The cursor moves with the batch, so a resumed send neither skips
+		// nor repeats anyone
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&sent).Error; err != nil {
+				return err
+			}
+			return tx.Model(b).Updates(map[string]interface{}{
+				"delivered":    gorm.Expr("delivered + ?", len(batch)),
+				"last_user_id": batch[len(batch)-1],
+			}).Error
+		})
+		if err != nil {
+			fail(err)
+			return
+		}
+		PublishNotifications(sent...)
+	}
+
+	now := time.Now()
+	s.db.Model(b).Updates(map[string]interface{}{"status": "sent", "sent_at": now})
+	log.Printf("Broadcast %d sent to %d users", b.ID, len(pending))
+}
+
+// remainingFrom is the index of the first recipient (sorted IDs) after the cursor
+func remainingFrom(userIDs []uint, lastUserID uint) int {
+	i, found := slices.BinarySearch(userIDs, lastUserID)
+	if found {
+		i++
+	}
+	return i
+}
+
+// RunScheduler sends due broadcasts every interval, or right away when one
+// is created. Meant to run in its own goroutine.
+func (s *BroadcastService) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDue(time.Now()); err != nil {
+			log.Printf("Broadcast scheduler failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-broadcastWake:
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidDevice   = errors.New("invalid device")
+	ErrUnknownCategory = errors.New("unknown notification category")
+)
+
+// NotificationCategories are the categories users can turn push on or off for:
+// the domain events plus the notifications sent directly
+var NotificationCategories = []string{
+	"general",
+	"announcement",
+	"availability",
+	"security",
+	EventMatchScheduled,
+	EventResultSubmitted,
+	EventResultDisputed,
+	EventPlayerSuspended,
+	EventTeamRegistrationApproved,
+	EventBanApplied,
+}
+
+var devicePlatforms = []string{"ios", "android", "web"}
+
+type DeviceService struct {
+	db *gorm.DB
+}
+
+func NewDeviceService() *DeviceService {
+	return &DeviceService{
+		db: database.GetDB(),
+	}
+}
+
+// Register adds the user's device, or refreshes it. Holding the token proves
+// the device is in the caller's hands now, so a registration of another user
+// is dropped, along with its queued pushes, before the caller's is added.
+func (s *DeviceService) Register(userID uint, token, platform string) (*models.DeviceToken, error) {
+	token = strings.TrimSpace(token)
+	platform = strings.ToLower(platform)
+	if token == "" || len(token) > 255 {
+		return nil, fmt.Errorf("%w: token is required (255 characters at most)", ErrInvalidDevice)
+	}
+	if !slices.Contains(devicePlatforms, platform) {
+		return nil, fmt.Errorf("%w: platform must be ios, android or web", ErrInvalidDevice)
+	}
+
+	var device models.DeviceToken
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", token).First(&device).Error
+		switch {
+		case err == nil && device.UserID == userID:
+			return tx.Model(&device).Updates(map[string]interface{}{"platform": platform, "last_seen_at": time.Now()}).Error
+		case err == nil:
+			// Pushes queued for the previous user must not reach the new one
+			if err := tx.Where("device_token_id = ? AND status = ?", device.ID, "pending").Delete(&models.PushDelivery{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&device).Error; err != nil {
+				return err
+			}
+			log.Printf("Device %d of user %d unregistered, the token was registered by user %d", device.ID, device.UserID, userID)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		device = models.DeviceToken{UserID: userID, Token: token, Platform: platform, LastSeenAt: time.Now()}
+		return tx.Create(&device).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// Devices lists the user's registered devices
+func (s *DeviceService) Devices(userID uint) ([]models.DeviceToken, error) {
+	var devices []models.DeviceToken
+	if err := s.db.Where("user_id = ?", userID).Order("last_seen_at desc").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Remove unregisters one of the user's devices, e.g. on sign out
+func (s *DeviceService) Remove(userID, deviceID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.DeviceToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Preferences returns the push setting of every category for the user
+func (s *DeviceService) Preferences(userID uint) ([]models.NotificationPreference, error) {
+	var saved []models.NotificationPreference
+	if err := s.db.Where("user_id = ?", userID).Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	push := make(map[string]bool, len(saved))
+	for _, p := range saved {
+		push[p.Category] = p.Push
+	}
+
+	prefs := make([]models.NotificationPreference, 0, len(NotificationCategories))
+	for _, category := range NotificationCategories {
+		enabled, ok := push[category]
+		prefs = append(prefs, models.NotificationPreference{UserID: userID, Category: category, Push: !ok || enabled})
+	}
+	return prefs, nil
+}
+
+// SetPreferences turns push on or off for the given categories, leaving the others
+func (s *DeviceService) SetPreferences(userID uint, push map[string]bool) ([]models.NotificationPreference, error) {
+	prefs := make([]models.NotificationPreference, 0, len(push))
+	for category, enabled := range push {
+		if !slices.Contains(NotificationCategories, category) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+		}
+		prefs = append(prefs, models.NotificationPreference{UserID: userID, Category: category, Push: enabled})
+	}
+
+	if len(prefs) > 0 {
+		if err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}},
+			DoUpdates: clause.AssignmentColumns([]string{"push"}),
+		}).Create(&prefs).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.Preferences(userID)
+}
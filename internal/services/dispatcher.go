@@ -0,0 +1,107 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dispatcher runs a queue of outgoing deliveries, such as pushes or webhooks.
+// A delivery is a row with a status, an attempt count and the time of its
+// next attempt; the dispatcher claims the pending ones that are due and hands
+// them to send, which records the outcome.
+type dispatcher[T any] struct {
+	name  string // For the logs
+	db    *gorm.DB
+	batch int           // Deliveries claimed per pass
+	lease time.Duration // How long a claimed delivery is left to its sender before another instance retries it
+	wake  chan struct{}
+	// claimKey returns the delivery's ID and its attempt count, bumped once claimed
+	claimKey func(d *T) (uint, *int)
+	// send delivers a claimed delivery, reporting whether it counts as tried
+	send  func(d *T) bool
+	prune func(now time.Time) error
+}
+
+// retryBackoff is the wait before the next try after the given number of
+// attempts: base after the first failure, doubled each time up to max
+func retryBackoff(base, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	return min(wait, max)
+}
+
+// wakeDispatcher has a dispatcher look for due deliveries right away
+func wakeDispatcher(wake chan struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchDue sends the deliveries whose time has come and returns how many it tried
+func (q *dispatcher[T]) dispatchDue(now time.Time) (int, error) {
+	var due []T
+	if err := q.db.Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		Order("next_attempt_at").Limit(q.batch).Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	tried := 0
+	for i := range due {
+		id, attempts := q.claimKey(&due[i])
+		// Claim it for the lease, so another instance running the dispatcher
+		// leaves it alone, and retries it if this one dies mid send
+		claim := q.db.Model(new(T)).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", id, "pending", now).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(q.lease),
+			})
+		if claim.Error != nil {
+			return tried, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		*attempts++
+		if q.send(&due[i]) {
+			tried++
+		}
+	}
+	return tried, nil
+}
+
+// run sends due deliveries every interval, or right away when woken, and
+// prunes hourly. Meant to run in its own goroutine.
+func (q *dispatcher[T]) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPruned time.Time
+	for {
+		now := time.Now()
+		for {
+			tried, err := q.dispatchDue(now)
+			if err != nil {
+				log.Printf("%s dispatcher failed: %v", q.name, err)
+			}
+			if err != nil || tried < q.batch {
+				break
+			}
+		}
+		if now.Sub(lastPruned) > time.Hour {
+			if err := q.prune(now); err != nil {
+				log.Printf("%s clean up failed: %v", q.name, err)
+			}
+			lastPruned = now
+		}
+		select {
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 5, want: 5 * time.Minute},
+		{attempts: 50, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(base, max, tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff after %d attempts = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/mailer"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidEmailMode = errors.New("email notifications must be off, instant or digest")
+	ErrNoEmailAddress   = errors.New("add an email address to the account first")
+)
+
+var emailModes = []string{"off", "instant", "digest"}
+
+// emailBatch caps how many notifications are emailed per pass
+const emailBatch = 200
+
+// digestShown caps how many notifications a digest lists; the rest are counted
+const digestShown = 20
+
+var emailTextTemplates = template.Must(template.New("email").Parse(`
+{{define "instant"}}Hi {{.Username}},
+
+{{.Notification.Message}}
+
+Open LeagueMaster: {{.AppURL}}
+
+You get an email for every notification. You can switch to a daily digest or turn emails off in the app.
+{{end}}
+
+{{define "digest"}}Hi {{.Username}},
+
+You have {{.Total}} unread notification{{if ne .Total 1}}s{{end}}:
+{{range .Notifications}}
+- {{.CreatedAt.Format "Jan 2, 15:04"}}: {{.Message}}{{end}}
+{{if .More}}
+...and {{.More}} more.
+{{end}}
+Open LeagueMaster: {{.AppURL}}
+
+This is your daily digest. You can switch to an email for every notification or turn emails off in the app.
+{{end}}
+`))
+
+var emailHTMLTemplates = htmltemplate.Must(htmltemplate.New("email").Parse(`
+{{define "instant"}}<!DOCTYPE html>
+<html><body style="font-family: sans-serif; color: #222;">
+<p>Hi {{.Username}},</p>
+<p>{{.Notification.Message}}</p>
+<p><a href="{{.AppURL}}">Open LeagueMaster</a></p>
+<p style="color: #888; font-size: 12px;">You get an email for every notification. You can switch to a daily digest or turn emails off in the app.</p>
+</body></html>
+{{end}}
+
+{{define "digest"}}<!DOCTYPE html>
+<html><body style="font-family: sans-serif; color: #222;">
+<p>Hi {{.Username}},</p>
+<p>You have {{.Total}} unread notification{{if ne .Total 1}}s{{end}}:</p>
+<ul>
+{{range .Notifications}}<li><span style="color: #888;">{{.CreatedAt.Format "Jan 2, 15:04"}}</span> {{.Message}}</li>
+{{end}}</ul>
+{{if .More}}<p>...and {{.More}} more.</p>{{end}}
+<p><a href="{{.AppURL}}">Open LeagueMaster</a></p>
+<p style="color: #888; font-size: 12px;">This is your daily digest. You can switch to an email for every notification or turn emails off in the app.</p>
+</body></html>
+{{end}}
+`))
+
+// emailData is what the email templates are rendered with
+type emailData struct {
+	Username      string
+	AppURL        string
+	Notification  models.Notification   // instant
+	Notifications []models.Notification // digest
+	Total         int
+	More          int
+}
+
+// renderEmail builds both bodies of an email from the named template
+func renderEmail(name, subject, to string, data emailData) (mailer.Message, error) {
+	var text, html bytes.Buffer
+	if err := emailTextTemplates.ExecuteTemplate(&text, name, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := emailHTMLTemplates.ExecuteTemplate(&html, name, data); err != nil {
+		return mailer.Message{}, err
+	}
+	return mailer.Message{To: to, Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// EmailNotificationService emails notifications to the users who opted in,
+// one email each (instant) or a daily digest of the unread ones
+type EmailNotificationService struct {
+	db     *gorm.DB
+	mailer mailer.Sender
+}
+
+func NewEmailNotificationService() *EmailNotificationService {
+	return &EmailNotificationService{
+		db:     database.GetDB(),
+		mailer: mailer.NewFromEnv(),
+	}
+}
+
+// Mode returns the user's email setting
+func (s *EmailNotificationService) Mode(userID uint) (string, error) {
+	var user models.User
+	if err := s.db.Select("id", "email_notifications").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	return user.EmailNotifications, nil
+}
+
+// SetMode opts the user in or out. Opting in needs a valid email address.
+func (s *EmailNotificationService) SetMode(userID uint, mode string) error {
+	if !slices.Contains(emailModes, mode) {
+		return ErrInvalidEmailMode
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if mode != "off" && (user.Email == nil || *user.Email == "") {
+		return ErrNoEmailAddress
+	}
+	if mode != "off" {
+		if err := CheckEmail(user.Email); err != nil {
+			return err
+		}
+	}
+	return s.db.Model(&user).Update("email_notifications", mode).Error
+}
+
+// optedIn selects the active users with an address and the given mode
+func (s *EmailNotificationService) optedIn(mode string) *gorm.DB {
+	return s.db.Model(&models.User{}).
+		Where("email_notifications = ? AND email IS NOT NULL AND email <> '' AND is_active = ?", mode, true)
+}
+
+// SendInstant emails each new unread notification of the users in instant
+// mode, and returns how many it sent. Older ones are left alone, so opting in
+// doesn't mail out the backlog.
+func (s *EmailNotificationService) SendInstant(now time.Time) (int, error) {
+	var pending []models.Notification
+	if err := s.db.Where("emailed_at IS NULL AND is_read = ? AND created_at >= ? AND user_id IN (?)",
+		false, now.Add(-utils.EnvDuration("EMAIL_INSTANT_MAX_AGE", time.Hour)), s.optedIn("instant").Select("id")).
+		Order("id").Limit(emailBatch).Find(&pending).Error; err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	userIDs := make([]uint, 0, len(pending))
+	for _, n := range pending {
+		userIDs = append(userIDs, n.UserID)
+	}
+	var users []models.User
+	if err := s.db.Where("id IN ?", uniqueIDs(userIDs)).Find(&users).Error; err != nil {
+		return 0, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	sent := 0
+	for _, n := range pending {
+		user, ok := byID[n.UserID]
+		if !ok || user.Email == nil {
+			continue
+		}
+		// Claim it, so another instance running the job doesn't send it too
+		claim := s.db.Model(&models.Notification{}).Where("id = ? AND emailed_at IS NULL", n.ID).Update("emailed_at", now)
+		if claim.Error != nil {
+			return sent, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		msg, err := renderEmail("instant", "LeagueMaster: "+truncate(strings.Join(strings.Fields(n.Message), " "), 60), *user.Email,
+			emailData{Username: user.Username, AppURL: os.Getenv("APP_BASE_URL"), Notification: n})
+		if err == nil {
+			err = s.mailer.Send(msg)
+		}
+		if err != nil {
+			// Try again on the next pass, until it is too old
+			log.Printf("Emailing notification %d: %v", n.ID, err)
+			s.db.Model(&models.Notification{}).Where("id = ?", n.ID).Update("emailed_at", nil)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// SendDigests emails every user in digest mode one summary of their unread
+// notifications not emailed yet, and returns how many digests it sent
+func (s *EmailNotificationService) SendDigests(now time.Time) (int, error) {
+	var users []models.User
+	if err := s.optedIn("digest").Find(&users).Error; err != nil {
+		return 0, err
+	}
+
+	since := now.Add(-utils.EnvDuration("EMAIL_DIGEST_LOOKBACK", 7*24*time.Hour))
+	sent := 0
+	for _, user := range users {
+		// Claim the notifications under a row lock, so another instance
+		// running the job finds them emailed already
+		var notifications []models.Notification
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND emailed_at IS NULL AND is_read = ? AND created_at >= ?", user.ID, false, since).
+				Order("id desc").Find(&notifications).Error; err != nil {
+				return err
+			}
+			if len(notifications) == 0 {
+				return nil
+			}
+			return tx.Model(&models.Notification{}).Where("id IN ?", notificationIDs(notifications)).Update("emailed_at", now).Error
+		})
+		if err != nil {
+			return sent, err
+		}
+		if len(notifications) == 0 {
+			continue
+		}
+
+		data := emailData{Username: user.Username, AppURL: os.Getenv("APP_BASE_URL"), Total: len(notifications)}
+		data.Notifications = notifications[:min(len(notifications), digestShown)]
+		data.More = len(notifications) - len(data.Notifications)
+
+		msg, err := renderEmail("digest", fmt.Sprintf("Your LeagueMaster digest: %d unread", len(notifications)), *user.Email, data)
+		if err == nil {
+			err = s.mailer.Send(msg)
+		}
+		if err != nil {
+			log.Printf("Emailing digest to user %d: %v", user.ID, err)
+			s.db.Model(&models.Notification{}).Where("id IN ?", notificationIDs(notifications)).Update("emailed_at", nil)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// RunEmails sends instant emails every interval and the digests once a day at
+// digestHour (server local time). Meant to run in its own goroutine.
+func (s *EmailNotificationService) RunEmails(interval time.Duration, digestHour int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastDigest := ""
+	for {
+		now := time.Now()
+		if _, err := s.SendInstant(now); err != nil {
+			log.Printf("Notification emails failed: %v", err)
+		}
+		// Claimed notifications aren't sent twice, so a restart within the
+		// hour (or several instances) doesn't duplicate digests
+		if today := now.Format("2006-01-02"); now.Hour() == digestHour && lastDigest != today {
+			lastDigest = today
+			if _, err := s.SendDigests(now); err != nil {
+				log.Printf("Notification digests failed: %v", err)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+func notificationIDs(notifications []models.Notification) []uint {
+	ids := make([]uint, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// truncate shortens s to at most n runes, marking the cut
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Domain events published on the event bus
+const (
+	EventMatchScheduled           = "match.scheduled"
+	EventMatchCompleted           = "match.completed"
+	EventResultSubmitted          = "match.result_submitted"
+	EventResultDisputed           = "match.result_disputed"
+	EventStandingsChanged         = "standings.changed"
+	EventPlayerSuspended          = "player.suspended"
+	EventTeamRegistrationApproved = "team.registration_approved"
+	EventBanApplied               = "user.banned"
+)
+
+// DomainEvent is something that happened in the league. The IDs say what it
+// is about (zero when not relevant); Data holds the values the notification
+// templates can use.
+type DomainEvent struct {
+	Name         string            `json:"event"`
+	MatchID      uint              `json:"match_id,omitempty"`
+	TournamentID uint              `json:"tournament_id,omitempty"`
+	TeamID       uint              `json:"team_id,omitempty"`
+	PlayerID     uint              `json:"player_id,omitempty"`
+	UserID       uint              `json:"user_id,omitempty"`
+	Data         map[string]string `json:"data"`
+	OccurredAt   time.Time         `json:"occurred_at"`
+}
+
+// EventHandler reacts to a domain event
+type EventHandler func(event DomainEvent)
+
+// EventBus delivers domain events to subscribers in the background, so a slow
+// or failing subscriber never holds up the request that caused the event.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]EventHandler
+}
+
+// AllEvents subscribes a handler to every event
+const AllEvents = "*"
+
+var eventBus = &EventBus{handlers: make(map[string][]EventHandler)}
+
+// Events returns the shared event bus
+func Events() *EventBus {
+	return eventBus
+}
+
+// Subscribe registers a handler for an event name, or AllEvents
+func (b *EventBus) Subscribe(name string, handler EventHandler) {
+	b.mu.Lock()
+	b.handlers[name] = append(b.handlers[name], handler)
+	b.mu.Unlock()
+}
+
+// stamp fills in the time and data of an event that didn't set them
+func (e *DomainEvent) stamp() {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	if e.Data == nil {
+		e.Data = map[string]string{}
+	}
+}
+
+// Publish hands the event to its subscribers. Call it once the change is committed.
+func (b *EventBus) Publish(event DomainEvent) {
+	event.stamp()
+
+	b.mu.RLock()
+	handlers := append(append([]EventHandler(nil), b.handlers[event.Name]...), b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go func(handler EventHandler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event handler for %s panicked: %v", event.Name, r)
+				}
+			}()
+			handler(event)
+		}(handler)
+	}
+}
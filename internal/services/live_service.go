@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/pubsub"
+	"gorm.io/gorm"
+)
+
+// Live feed event types, besides the match event types (goal, card_yellow, card_red)
+const (
+	LiveEventStatus = "status"
+)
+
+// LiveEvent is pushed to the spectators of a match and of its tournament.
+// It carries the score after the event, so a client can simply overwrite.
+type LiveEvent struct {
+	Type         string             `json:"type"`
+	MatchID      uint               `json:"match_id"`
+	TournamentID uint               `json:"tournament_id"`
+	Status       string             `json:"status"`
+	ScoreA       int                `json:"score_a"`
+	ScoreB       int                `json:"score_b"`
+	Event        *models.MatchEvent `json:"event,omitempty"`
+	At           time.Time          `json:"at"`
+}
+
+// liveBroker carries live events on this instance. Spectator streams also
+// resend a snapshot now and then, which covers events recorded elsewhere.
+var liveBroker = pubsub.NewBroker(32, 0)
+
+func matchFeedTopic(matchID uint) string {
+	return fmt.Sprintf("match:%d", matchID)
+}
+
+func tournamentFeedTopic(tournamentID uint) string {
+	return fmt.Sprintf("tournament:%d", tournamentID)
+}
+
+// publishLive sends an event to the match and tournament feeds. Call it once
+// the change is committed.
+func publishLive(match *models.Match, eventType string, event *models.MatchEvent) {
+	live := LiveEvent{
+		Type:         eventType,
+		MatchID:      match.ID,
+		TournamentID: match.TournamentID,
+		Status:       match.Status,
+		ScoreA:       match.ScoreA,
+		ScoreB:       match.ScoreB,
+		Event:        event,
+		At:           time.Now(),
+	}
+	liveBroker.Publish(matchFeedTopic(match.ID), live)
+	liveBroker.Publish(tournamentFeedTopic(match.TournamentID), live)
+}
+
+// LiveService serves the public live score feeds
+type LiveService struct {
+	db *gorm.DB
+}
+
+func NewLiveService() *LiveService {
+	return &LiveService{
+		db: database.GetDB(),
+	}
+}
+
+func (s *LiveService) withTeamsAndEvents(q *gorm.DB) *gorm.DB {
+	return q.Preload("TeamA").Preload("TeamB").
+		Preload("MatchEvents", func(db *gorm.DB) *gorm.DB { return db.Order("minute, id") })
+}
+
+// LiveMatches lists the matches in progress, optionally in one tournament
+func (s *LiveService) LiveMatches(tournamentID uint) ([]models.Match, error) {
+	q := s.db.Preload("TeamA").Preload("TeamB").Where("status = ?", "live")
+	if tournamentID != 0 {
+		q = q.Where("tournament_id = ?", tournamentID)
+	}
+	var matches []models.Match
+	if err := q.Order("started_at").Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// MatchSnapshot is the full state of a match: teams, score and events so far
+func (s *LiveService) MatchSnapshot(matchID uint) (*models.Match, error) {
+	var match models.Match
+	if err := s.withTeamsAndEvents(s.db).First(&match, matchID).Error; err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// TournamentSnapshot is the state of every match in progress in a tournament
+func (s *LiveService) TournamentSnapshot(tournamentID uint) ([]models.Match, error) {
+	var tournament models.Tournament
+	if err := s.db.First(&tournament, tournamentID).Error; err != nil {
+		return nil, err
+	}
+	var matches []models.Match
+	if err := s.withTeamsAndEvents(s.db).Where("tournament_id = ? AND status = ?", tournamentID, "live").
+		Order("started_at").Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// SubscribeMatch opens the feed of one match. Messages are LiveEvents.
+func (s *LiveService) SubscribeMatch(matchID uint) *pubsub.Subscription {
+	return liveBroker.Subscribe(matchFeedTopic(matchID))
+}
+
+// SubscribeTournament opens the feed of every match in a tournament
+func (s *LiveService) SubscribeTournament(tournamentID uint) *pubsub.Subscription {
+	return liveBroker.Subscribe(tournamentFeedTopic(tournamentID))
+}
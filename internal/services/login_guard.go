@@ -0,0 +1,424 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// ThrottleError tells the client when it may try again. It matches
+// ErrTooManyAttempts with errors.Is.
+type ThrottleError struct {
+	RetryAfter time.Duration
+	Locked     bool // A lockout rather than the backoff between attempts
+}
+
+func (e *ThrottleError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked, try again in %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// Attempt is the failure record of one username or client IP
+type Attempt struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil *time.Time
+}
+
+// AttemptStore keeps failure records. The in-process store suits a single
+// instance; deployments running several instances need a shared backend,
+// either the database store or their own (see SetAttemptStore).
+type AttemptStore interface {
+	// Get returns the record for a key, or nil when there is none
+	Get(key string) (*Attempt, error)
+	// RecordFailure counts a failure, starting over when the previous one is
+	// older than window or the lockout has run out, and returns the new record
+	RecordFailure(key string, now time.Time, window time.Duration) (*Attempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// NewAttemptStoreFromEnv builds the store configured by LOGIN_ATTEMPT_STORE
+// (memory by default, or database)
+func NewAttemptStoreFromEnv() AttemptStore {
+	switch strings.ToLower(os.Getenv("LOGIN_ATTEMPT_STORE")) {
+	case "database":
+		return NewDBAttemptStore(database.GetDB())
+	default:
+		return NewMemoryAttemptStore()
+	}
+}
+
+// MemoryAttemptStore keeps records in process memory
+type MemoryAttemptStore struct {
+	mu         sync.Mutex
+	attempts   map[string]*Attempt
+	lastPruned time.Time
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]*Attempt)}
+}
+
+func (s *MemoryAttemptStore) Get(key string) (*Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *MemoryAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop stale records once in a while so the map doesn't grow forever
+	if now.Sub(s.lastPruned) > window {
+		for k, a := range s.attempts {
+			if now.Sub(a.LastFailure) > window && (a.LockedUntil == nil || now.After(*a.LockedUntil)) {
+				delete(s.attempts, k)
+			}
+		}
+		s.lastPruned = now
+	}
+
+	attempt, ok := s.attempts[key]
+	if !ok || attemptExpired(attempt, now, window) {
+		attempt = &Attempt{}
+		s.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailure = now
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *MemoryAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = &Attempt{LastFailure: time.Now()}
+		s.attempts[key] = attempt
+	}
+	attempt.LockedUntil = &until
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	delete(s.attempts, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// DBAttemptStore keeps records in the login_attempts table, shared by all instances
+type DBAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewDBAttemptStore(db *gorm.DB) *DBAttemptStore {
+	return &DBAttemptStore{db: db}
+}
+
+func (s *DBAttemptStore) Get(key string) (*Attempt, error) {
+	var record models.LoginAttempt
+	err := s.db.Where("identifier = ?", key).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Attempt{Failures: record.Failures, LastFailure: record.LastFailureAt, LockedUntil: record.LockedUntil}, nil
+}
+
+func (s *DBAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*Attempt, error) {
+	// Drop stale records while we're here
+	s.db.Where("updated_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-window), now).
+		Delete(&models.LoginAttempt{})
+
+	var attempt *Attempt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		seed := models.LoginAttempt{Identifier: key, LastFailureAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+			return err
+		}
+
+		// Row lock so concurrent failures on other instances are all counted
+		var record models.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("identifier = ?", key).First(&record).Error; err != nil {
+			return err
+		}
+
+		current := &Attempt{Failures: record.Failures, LastFailure: record.LastFailureAt, LockedUntil: record.LockedUntil}
+		if attemptExpired(current, now, window) {
+			current = &Attempt{}
+		}
+		current.Failures++
+		current.LastFailure = now
+
+		if err := tx.Model(&record).Select("failures", "last_failure_at", "locked_until").Updates(map[string]interface{}{
+			"failures":        current.Failures,
+			"last_failure_at": current.LastFailure,
+			"locked_until":    current.LockedUntil,
+		}).Error; err != nil {
+			return err
+		}
+		attempt = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func (s *DBAttemptStore) Lock(key string, until time.Time) error {
+	return s.db.Model(&models.LoginAttempt{}).Where("identifier = ?", key).Update("locked_until", until).Error
+}
+
+func (s *DBAttemptStore) Reset(key string) error {
+	return s.db.Where("identifier = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// attemptExpired reports whether a record should start over: the last failure
+// left the window, or a lockout has run out
+func attemptExpired(attempt *Attempt, now time.Time, window time.Duration) bool {
+	if attempt.LockedUntil != nil {
+		return now.After(*attempt.LockedUntil)
+	}
+	return now.Sub(attempt.LastFailure) > window
+}
+
+// LoginPolicy sets the backoff and lockout thresholds
+type LoginPolicy struct {
+	FreeAttempts         int           // Failures allowed before the backoff starts
+	BackoffBase          time.Duration // Wait after the first throttled failure, doubled each time
+	BackoffMax           time.Duration
+	UserLockoutThreshold int // Failures on one username before it is locked
+	IPLockoutThreshold   int // Failures from one IP before it is locked
+	LockoutDuration      time.Duration
+	Window               time.Duration // Failures older than this are forgotten
+	MFATokenAttempts     int           // Wrong codes allowed per "mfa pending" token before it is refused
+}
+
+func loginPolicyFromEnv() LoginPolicy {
+	return LoginPolicy{
+		FreeAttempts:         utils.EnvInt("LOGIN_FREE_ATTEMPTS", 3),
+		BackoffBase:          utils.EnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		BackoffMax:           utils.EnvDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+		UserLockoutThreshold: utils.EnvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		IPLockoutThreshold:   utils.EnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockoutDuration:      utils.EnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		Window:               utils.EnvDuration("LOGIN_ATTEMPT_WINDOW", time.Hour),
+		MFATokenAttempts:     utils.EnvInt("MFA_TOKEN_MAX_ATTEMPTS", 5),
+	}
+}
+
+// LoginGuard throttles password logins per username and per client IP:
+// after a few failures each further attempt has to wait exponentially
+// longer, and past a threshold the username or IP is locked for a while.
+type LoginGuard struct {
+	store  AttemptStore
+	policy LoginPolicy
+}
+
+var (
+	loginGuard     *LoginGuard
+	loginGuardOnce sync.Once
+)
+
+// LoginAttempts returns the shared login guard, built from the environment on
+// first use (after the database is connected)
+func LoginAttempts() *LoginGuard {
+	loginGuardOnce.Do(func() {
+		if loginGuard == nil {
+			loginGuard = &LoginGuard{store: NewAttemptStoreFromEnv(), policy: loginPolicyFromEnv()}
+		}
+	})
+	return loginGuard
+}
+
+// SetAttemptStore plugs in another backend (e.g. a Redis store) at startup,
+// before the first login
+func SetAttemptStore(store AttemptStore) {
+	loginGuard = &LoginGuard{store: store, policy: loginPolicyFromEnv()}
+}
+
+func userAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// The second login step is counted per user and per "mfa pending" token
+func mfaUserAttemptKey(userID uint) string {
+	return fmt.Sprintf("mfa:user:%d", userID)
+}
+
+func mfaTokenAttemptKey(tokenID string) string {
+	return "mfa:token:" + tokenID
+}
+
+func (g *LoginGuard) keys(username, ip string) []string {
+	keys := []string{userAttemptKey(username)}
+	if ip != "" {
+		keys = append(keys, ipAttemptKey(ip))
+	}
+	return keys
+}
+
+// backoff is the wait before the next attempt after n failures
+func (g *LoginGuard) backoff(n int) time.Duration {
+	over := n - g.policy.FreeAttempts
+	if over < 0 {
+		return 0
+	}
+	if over > 20 {
+		return g.policy.BackoffMax
+	}
+	wait := g.policy.BackoffBase << over
+	if wait > g.policy.BackoffMax {
+		return g.policy.BackoffMax
+	}
+	return wait
+}
+
+// Check refuses an attempt while the username or IP is locked or backing off.
+// Store errors are logged and let the attempt through.
+func (g *LoginGuard) Check(username, ip string) error {
+	return g.check(g.keys(username, ip))
+}
+
+// CheckMFA refuses a second step while the user is locked or backing off, or
+// once the "mfa pending" token ran out of attempts
+func (g *LoginGuard) CheckMFA(userID uint, tokenID string) error {
+	return g.check([]string{mfaUserAttemptKey(userID), mfaTokenAttemptKey(tokenID)})
+}
+
+func (g *LoginGuard) check(keys []string) error {
+	now := time.Now()
+	var refused *ThrottleError
+	for _, key := range keys {
+		attempt, err := g.store.Get(key)
+		if err != nil {
+			log.Printf("Login attempt store: %v", err)
+			continue
+		}
+		if attempt == nil {
+			continue
+		}
+
+		var throttle *ThrottleError
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			throttle = &ThrottleError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+		} else if attempt.LockedUntil == nil && now.Sub(attempt.LastFailure) <= g.policy.Window {
+			if next := attempt.LastFailure.Add(g.backoff(attempt.Failures)); now.Before(next) {
+				throttle = &ThrottleError{RetryAfter: next.Sub(now)}
+			}
+		}
+		if throttle != nil && (refused == nil || throttle.RetryAfter > refused.RetryAfter) {
+			refused = throttle
+		}
+	}
+	if refused != nil {
+		return refused
+	}
+	return nil
+}
+
+// Fail records a failed attempt and locks the username or IP once it crosses
+// its threshold. It reports whether the username was locked just now.
+func (g *LoginGuard) Fail(username, ip string) bool {
+	thresholds := map[string]int{userAttemptKey(username): g.policy.UserLockoutThreshold}
+	if ip != "" {
+		thresholds[ipAttemptKey(ip)] = g.policy.IPLockoutThreshold
+	}
+	return g.fail(thresholds)[userAttemptKey(username)]
+}
+
+// FailMFA records a wrong second step code. The user is locked like for
+// passwords; the token is refused for good after a few tries. It reports
+// whether the user was locked just now.
+func (g *LoginGuard) FailMFA(userID uint, tokenID string) bool {
+	return g.fail(map[string]int{
+		mfaUserAttemptKey(userID):   g.policy.UserLockoutThreshold,
+		mfaTokenAttemptKey(tokenID): g.policy.MFATokenAttempts,
+	})[mfaUserAttemptKey(userID)]
+}
+
+// fail counts a failure on each key and locks those reaching their threshold.
+// It returns the keys locked just now.
+func (g *LoginGuard) fail(thresholds map[string]int) map[string]bool {
+	now := time.Now()
+	locked := make(map[string]bool)
+	for key, threshold := range thresholds {
+		attempt, err := g.store.RecordFailure(key, now, g.policy.Window)
+		if err != nil {
+			log.Printf("Login attempt store: %v", err)
+			continue
+		}
+		if attempt.Failures != threshold {
+			continue
+		}
+		if err := g.store.Lock(key, now.Add(g.policy.LockoutDuration)); err != nil {
+			log.Printf("Login attempt store: %v", err)
+			continue
+		}
+		log.Printf("Login locked for %s after %d failed attempts", key, attempt.Failures)
+		locked[key] = true
+	}
+	return locked
+}
+
+// Succeed clears the username's failures. The IP record is kept, so a valid
+// account can't be used to reset the counter of an IP guessing others.
+func (g *LoginGuard) Succeed(username string) {
+	if err := g.store.Reset(userAttemptKey(username)); err != nil {
+		log.Printf("Login attempt store: %v", err)
+	}
+}
+
+// SucceedMFA clears the user's second step failures
+func (g *LoginGuard) SucceedMFA(userID uint) {
+	if err := g.store.Reset(mfaUserAttemptKey(userID)); err != nil {
+		log.Printf("Login attempt store: %v", err)
+	}
+}
+
+// Unlock lifts a lockout and clears the failures of a user, password and second step
+func (g *LoginGuard) Unlock(username string, userID uint) error {
+	if err := g.store.Reset(userAttemptKey(username)); err != nil {
+		return err
+	}
+	return g.store.Reset(mfaUserAttemptKey(userID))
+}
+
+// LockoutDuration is how long a lockout lasts
+func (g *LoginGuard) LockoutDuration() time.Duration {
+	return g.policy.LockoutDuration
+}
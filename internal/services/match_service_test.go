@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+)
+
+func resultMatch(status string, submittedBy *uint) *models.Match {
+	teamA, teamB := uint(1), uint(2)
+	return &models.Match{TeamAID: &teamA, TeamBID: &teamB, Status: status, ResultSubmittedByTeamID: submittedBy}
+}
+
+func TestCheckResultSubmission(t *testing.T) {
+	teamA, teamB := uint(1), uint(2)
+	tests := []struct {
+		name  string
+		match *models.Match
+		team  uint
+		want  error
+	}{
+		{name: "scheduled match", match: resultMatch("scheduled", nil), team: teamA},
+		{name: "live match", match: resultMatch("live", nil), team: teamB},
+		{name: "team not playing", match: resultMatch("live", nil), team: 3, want: ErrTeamNotInMatch},
+		{name: "reporter corrects its result", match: resultMatch("pending_verification", &teamA), team: teamA},
+		{name: "other team overwrites a pending result", match: resultMatch("pending_verification", &teamA), team: teamB, want: ErrResultPending},
+		{name: "disputed result", match: resultMatch("disputed", &teamA), team: teamA, want: ErrResultClosed},
+		{name: "disputed result, other team", match: resultMatch("disputed", &teamA), team: teamB, want: ErrResultClosed},
+		{name: "completed match", match: resultMatch("completed", &teamA), team: teamB, want: ErrResultClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkResultSubmission(tt.match, tt.team); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPendingResult(t *testing.T) {
+	teamA, teamB := uint(1), uint(2)
+	tests := []struct {
+		name  string
+		match *models.Match
+		team  uint
+		want  error
+	}{
+		{name: "other team confirms", match: resultMatch("pending_verification", &teamA), team: teamB},
+		{name: "reporter confirms its own result", match: resultMatch("pending_verification", &teamA), team: teamA, want: ErrOwnResult},
+		{name: "team not playing", match: resultMatch("pending_verification", &teamA), team: 3, want: ErrTeamNotInMatch},
+		{name: "nothing reported", match: resultMatch("live", nil), team: teamB, want: ErrNoPendingResult},
+		{name: "already disputed", match: resultMatch("disputed", &teamA), team: teamB, want: ErrNoPendingResult},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkPendingResult(tt.match, tt.team); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEventRecording(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	tests := []struct {
+		name        string
+		status      string
+		scheduledAt *time.Time
+		want        error
+	}{
+		{name: "live match", status: "live"},
+		{name: "scheduled past kick-off", status: "scheduled", scheduledAt: &past},
+		{name: "scheduled before kick-off", status: "scheduled", scheduledAt: &future, want: ErrMatchNotStarted},
+		{name: "scheduled without kick-off", status: "scheduled", want: ErrMatchNotStarted},
+		{name: "result pending", status: "pending_verification", scheduledAt: &past, want: ErrResultReported},
+		{name: "result disputed", status: "disputed", scheduledAt: &past, want: ErrResultReported},
+		{name: "completed match", status: "completed", scheduledAt: &past, want: ErrMatchCompleted},
+	}
+	for _, tt := range tests {
+		match := resultMatch(tt.status, nil)
+		match.ScheduledAt = tt.scheduledAt
+		if err := checkEventRecording(match, now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not set up")
+	ErrMFAMandatory       = errors.New("two-factor authentication is mandatory for admins")
+	ErrMFAEnrollmentFirst = errors.New("set up two-factor authentication first")
+	ErrMFATokenUsed       = errors.New("this sign-in was already completed, log in again")
+)
+
+// recoveryCodeCount is how many recovery codes are handed out at a time
+const recoveryCodeCount = 10
+
+// MFAService manages TOTP two-factor authentication: enrolment, the second
+// login step and recovery codes.
+type MFAService struct {
+	db   *gorm.DB
+	auth *AuthService
+}
+
+func NewMFAService() *MFAService {
+	return &MFAService{
+		db:   database.GetDB(),
+		auth: NewAuthService(),
+	}
+}
+
+// MFAEnrollment is shown once, as text and as a QR code of the URI
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// Enroll generates a new secret for the user. 2FA is enabled once Confirm
+// sees a valid code from the authenticator app.
+func (s *MFAService) Enroll(userID uint) (*MFAEnrollment, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "LeagueMaster"
+	}
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPURI(issuer, user.Username, secret),
+	}, nil
+}
+
+// MFAToken identifies the "mfa pending" token a second step comes with
+type MFAToken struct {
+	ID        string // jti
+	ExpiresAt time.Time
+}
+
+// useMFAToken marks the token used; each one starts a single session
+func useMFAToken(tx *gorm.DB, token MFAToken) error {
+	// Drop the expired ones while we're here
+	if err := tx.Where("expires_at < ?", time.Now()).Delete(&models.UsedMFAToken{}).Error; err != nil {
+		return err
+	}
+	if token.ID == "" {
+		return ErrMFATokenUsed
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UsedMFAToken{TokenID: token.ID, ExpiresAt: token.ExpiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFATokenUsed
+	}
+	return nil
+}
+
+// MFAConfirmation holds the recovery codes, shown only once, and a session
+// when enrolment was the last step of a login.
+type MFAConfirmation struct {
+	RecoveryCodes []string
+	Tokens        *TokenPair
+	User          *models.User
+}
+
+// Confirm enables 2FA after checking a code against the enrolled secret.
+// With the "mfa pending" token of a login (admins enrolling during login) it
+// also signs the user in.
+func (s *MFAService) Confirm(userID uint, code string, pending *MFAToken) (*MFAConfirmation, error) {
+	confirmation := &MFAConfirmation{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.TOTPEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		if user.TOTPSecret == "" {
+			return ErrMFAEnrollmentFirst
+		}
+
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+		if err := tx.Model(&user).Select("totp_enabled", "totp_last_step").Updates(&user).Error; err != nil {
+			return err
+		}
+
+		codes, err := replaceRecoveryCodes(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		confirmation.RecoveryCodes = codes
+		confirmation.User = &user
+
+		if pending != nil {
+			if err := CheckAccount(tx, &user); err != nil {
+				return err
+			}
+			if err := useMFAToken(tx, *pending); err != nil {
+				return err
+			}
+			confirmation.Tokens, err = s.auth.issueTokens(tx, &user, "")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return confirmation, nil
+}
+
+// Verify is the second login step: it takes an authenticator code or a
+// recovery code and issues the session. Wrong codes are throttled per user
+// and per "mfa pending" token, and the token is spent once it succeeds.
+func (s *MFAService) Verify(userID uint, pending MFAToken, code, recoveryCode string) (*TokenPair, *models.User, error) {
+	guard := LoginAttempts()
+	if err := guard.CheckMFA(userID, pending.ID); err != nil {
+		log.Printf("Second step throttled for user %d: %v", userID, err)
+		return nil, nil, err
+	}
+
+	var tokens *TokenPair
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if !user.TOTPEnabled {
+			return ErrMFAEnrollmentFirst
+		}
+		if err := CheckAccount(tx, &user); err != nil {
+			return err
+		}
+
+		if recoveryCode != "" {
+			if err := useRecoveryCode(tx, user.ID, recoveryCode); err != nil {
+				return err
+			}
+		} else if err := checkTOTP(tx, &user, code); err != nil {
+			return err
+		}
+		if err := useMFAToken(tx, pending); err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = s.auth.issueTokens(tx, &user, "")
+		return err
+	})
+	if errors.Is(err, ErrInvalidMFACode) {
+		if guard.FailMFA(userID, pending.ID) {
+			s.auth.notifyLockout(&user)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	guard.SucceedMFA(userID)
+	return tokens, &user, nil
+}
+
+// Disable turns 2FA off after re-checking the password. Admins can't opt out.
+func (s *MFAService) Disable(userID uint, password string) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if user.Role == "admin" {
+		return ErrMFAMandatory
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return ErrInvalidCredentials
+	}
+	return s.reset(&user)
+}
+
+// Reset clears a user's 2FA, e.g. when an admin helps someone who lost their
+// phone. Their sessions are revoked; admins enrol again at the next login.
+func (s *MFAService) Reset(userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if err := s.reset(&user); err != nil {
+		return err
+	}
+	return s.auth.RevokeAllSessions(user.ID)
+}
+
+func (s *MFAService) reset(user *models.User) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Select("totp_enabled", "totp_secret", "totp_last_step").
+			Updates(map[string]interface{}{"totp_enabled": false, "totp_secret": "", "totp_last_step": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.MFARecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, after checking a current code
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if !user.TOTPEnabled {
+			return ErrMFANotEnrolled
+		}
+		if err := checkTOTP(tx, &user, code); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkTOTP validates a code and refuses one that was already used. The step
+// only moves forward if nobody used it meanwhile, so the same code can't sign
+// in twice concurrently.
+func checkTOTP(tx *gorm.DB, user *models.User, code string) error {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidMFACode
+	}
+	result := tx.Model(&models.User{}).Where("id = ? AND totp_last_step < ?", user.ID, step).Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func useRecoveryCode(tx *gorm.DB, userID uint, code string) error {
+	// Spend it only if still unused, so a code can't sign in twice concurrently
+	result := tx.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes issues a fresh set of codes, formatted XXXXX-XXXXX
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.RandomCode(10)
+		if err != nil {
+			return nil, err
+		}
+		record := models.MFARecoveryCode{UserID: userID, CodeHash: utils.HashToken(raw)}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
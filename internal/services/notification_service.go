@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/pubsub"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	notificationBroker     *pubsub.Broker
+	notificationBrokerOnce sync.Once
+)
+
+// notificationStreams wakes the open notification streams of a user. It only
+// reaches streams on this instance; streams also poll, which covers the others.
+// Built on first use, after the environment has been loaded.
+func notificationStreams() *pubsub.Broker {
+	notificationBrokerOnce.Do(func() {
+		notificationBroker = pubsub.NewBroker(16, utils.EnvInt("NOTIFICATION_STREAMS_PER_USER", 5))
+	})
+	return notificationBroker
+}
+
+func notificationTopic(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{
+		db: database.GetDB(),
+	}
+}
+
+// Send creates a notification and pushes it to the user's open streams
+func (s *NotificationService) Send(userID uint, category, message string) (*models.Notification, error) {
+	notification := models.Notification{UserID: userID, Category: category, Message: message}
+	if err := s.db.Create(&notification).Error; err != nil {
+		return nil, err
+	}
+	PublishNotifications(notification)
+	return &notification, nil
+}
+
+// Notify sends the same message to several users
+func (s *NotificationService) Notify(userIDs []uint, category, message string) ([]models.Notification, error) {
+	notifications := make([]models.Notification, 0, len(userIDs))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range userIDs {
+			notification := models.Notification{UserID: id, Category: category, Message: message}
+			if err := tx.Create(&notification).Error; err != nil {
+				return err
+			}
+			notifications = append(notifications, notification)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	PublishNotifications(notifications...)
+	return notifications, nil
+}
+
+// PublishNotifications pushes notifications created elsewhere to their users'
+// streams and queues them for their devices. Call it once the transaction
+// that created them has committed.
+func PublishNotifications(notifications ...models.Notification) {
+	for _, n := range notifications {
+		notificationStreams().Publish(notificationTopic(n.UserID), n)
+	}
+	if err := NewPushService().Queue(notifications); err != nil {
+		log.Printf("Queueing push notifications: %v", err)
+	}
+}
+
+// Subscribe opens a user's notification feed. Messages only signal that
+// something new arrived; read it with Since.
+func (s *NotificationService) Subscribe(userID uint) *pubsub.Subscription {
+	return notificationStreams().Subscribe(notificationTopic(userID))
+}
+
+// Since returns the user's notifications after the given ID, oldest first
+func (s *NotificationService) Since(userID, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := s.db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// LatestID is the ID of the user's newest notification, or 0
+func (s *NotificationService) LatestID(userID uint) (uint, error) {
+	var id uint
+	err := s.db.Model(&models.Notification{}).Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// InboxFilter narrows a page of the inbox
+type InboxFilter struct {
+	Before     uint // Cursor: only notifications older than this ID
+	Limit      int
+	UnreadOnly bool
+	Archived   bool // List the archive instead of the inbox
+	Category   string
+}
+
+// InboxPage is one page of notifications, newest first
+type InboxPage struct {
+	Notifications []models.Notification `json:"notifications"`
+	NextCursor    string                `json:"next_cursor,omitempty"` // Pass back as ?cursor= for the next page; empty on the last one
+}
+
+// maxInboxPage caps the page size, and how many notifications one bulk update may name
+const maxInboxPage = 100
+
+// Inbox returns a page of the user's notifications. Paging by ID keeps pages
+// stable while new notifications arrive.
+func (s *NotificationService) Inbox(userID uint, filter InboxFilter) (*InboxPage, error) {
+	limit := min(filter.Limit, maxInboxPage)
+	if limit <= 0 {
+		limit = 20
+	}
+
+	q := s.db.Where("user_id = ?", userID)
+	if filter.Archived {
+		q = q.Where("archived_at IS NOT NULL")
+	} else {
+		q = q.Where("archived_at IS NULL")
+	}
+	if filter.Before != 0 {
+		q = q.Where("id < ?", filter.Before)
+	}
+	if filter.UnreadOnly {
+		q = q.Where("is_read = ?", false)
+	}
+	if filter.Category != "" {
+		q = q.Where("category = ?", filter.Category)
+	}
+
+	var notifications []models.Notification
+	if err := q.Order("id desc").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	page := &InboxPage{Notifications: notifications}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(notifications[limit-1].ID), 10)
+	}
+	return page, nil
+}
+
+// UnreadCount counts the unread notifications in the user's inbox
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND archived_at IS NULL", userID, false).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks the given notifications of the user as read, or all of them
+// when ids is empty, and returns how many changed
+func (s *NotificationService) MarkRead(userID uint, ids []uint) (int64, error) {
+	q := s.db.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	result := q.Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+// Archive moves notifications out of the inbox, or back when archived is false
+func (s *NotificationService) Archive(userID uint, ids []uint, archived bool) (int64, error) {
+	q := s.db.Model(&models.Notification{}).Where("user_id = ? AND id IN ?", userID, ids)
+	var result *gorm.DB
+	if archived {
+		result = q.Where("archived_at IS NULL").Update("archived_at", time.Now())
+	} else {
+		result = q.Where("archived_at IS NOT NULL").Update("archived_at", nil)
+	}
+	return result.RowsAffected, result.Error
+}
+
+// Delete removes the given notifications of the user
+func (s *NotificationService) Delete(userID uint, ids []uint) (int64, error) {
+	result := s.db.Where("user_id = ? AND id IN ?", userID, ids).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// NotificationRetention sets how long notifications are kept
+type NotificationRetention struct {
+	Read time.Duration // Read or archived notifications
+	All  time.Duration // Any notification, read or not
+}
+
+// expiryBatch caps how many notifications are deleted per statement, to keep locks short
+const expiryBatch = 1000
+
+// Expire deletes the notifications past their retention and returns how many
+func (s *NotificationService) Expire(now time.Time, retention NotificationRetention) (int64, error) {
+	var total int64
+	deleteAll := func(query string, args ...interface{}) error {
+		for {
+			result := s.db.Where(query, args...).Limit(expiryBatch).Delete(&models.Notification{})
+			if result.Error != nil {
+				return result.Error
+			}
+			total += result.RowsAffected
+			if result.RowsAffected < expiryBatch {
+				return nil
+			}
+		}
+	}
+
+	readBefore := now.Add(-retention.Read)
+	if err := deleteAll("(is_read = ? OR archived_at IS NOT NULL) AND created_at < ?", true, readBefore); err != nil {
+		return total, err
+	}
+	if err := deleteAll("created_at < ?", now.Add(-retention.All)); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// RunExpiry deletes expired notifications every interval. Meant to run in its
+// own goroutine.
+func (s *NotificationService) RunExpiry(interval time.Duration, retention NotificationRetention) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.Expire(time.Now(), retention)
+		if err != nil {
+			log.Printf("Notification expiry failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired notifications", deleted)
+		}
+		<-ticker.C
+	}
+}
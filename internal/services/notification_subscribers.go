@@ -0,0 +1,176 @@
+package services
+
+import (
+	"log"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+// recipientResolver picks the users an event should notify
+type recipientResolver func(db *gorm.DB, event DomainEvent) ([]uint, error)
+
+var notificationRecipients = map[string]recipientResolver{
+	// Everyone running or playing for either team
+	EventMatchScheduled: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		teams, err := matchTeamIDs(db, event.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		managers, err := teamManagerIDs(db, teams)
+		if err != nil {
+			return nil, err
+		}
+		players, err := teamPlayerUserIDs(db, teams)
+		if err != nil {
+			return nil, err
+		}
+		return append(managers, players...), nil
+	},
+	// The other team, who has to confirm or dispute
+	EventResultSubmitted: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		teams, err := matchTeamIDs(db, event.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		var opponents []uint
+		for _, id := range teams {
+			if id != event.TeamID {
+				opponents = append(opponents, id)
+			}
+		}
+		return teamManagerIDs(db, opponents)
+	},
+	// Both teams and the admins who can resolve the match
+	EventResultDisputed: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		teams, err := matchTeamIDs(db, event.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		managers, err := teamManagerIDs(db, teams)
+		if err != nil {
+			return nil, err
+		}
+		admins, err := adminsWithPermission(db, PermMatchResolve, event.TournamentID)
+		if err != nil {
+			return nil, err
+		}
+		return append(managers, admins...), nil
+	},
+	// The player's own account and the people running their team
+	EventPlayerSuspended: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		var users []uint
+		if err := db.Model(&models.User{}).Where("player_id = ?", event.PlayerID).Pluck("id", &users).Error; err != nil {
+			return nil, err
+		}
+		if event.TeamID == 0 {
+			return users, nil
+		}
+		managers, err := teamManagerIDs(db, []uint{event.TeamID})
+		if err != nil {
+			return nil, err
+		}
+		return append(users, managers...), nil
+	},
+	EventTeamRegistrationApproved: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		return teamManagerIDs(db, []uint{event.TeamID})
+	},
+	EventBanApplied: func(db *gorm.DB, event DomainEvent) ([]uint, error) {
+		return []uint{event.UserID}, nil
+	},
+}
+
+// RegisterNotificationSubscribers turns domain events into notifications for
+// the users concerned, using the (admin editable) templates
+func RegisterNotificationSubscribers(bus *EventBus) {
+	for name, resolve := range notificationRecipients {
+		bus.Subscribe(name, func(event DomainEvent) {
+			db := database.GetDB()
+			userIDs, err := resolve(db, event)
+			if err != nil {
+				log.Printf("Notifications for %s: %v", event.Name, err)
+				return
+			}
+			userIDs = uniqueIDs(userIDs)
+			if len(userIDs) == 0 {
+				return
+			}
+
+			message, err := NewNotificationTemplateService().Render(event)
+			if err != nil {
+				log.Printf("Notifications for %s: %v", event.Name, err)
+				return
+			}
+			if _, err := NewNotificationService().Notify(userIDs, event.Name, message); err != nil {
+				log.Printf("Notifications for %s: %v", event.Name, err)
+			}
+		})
+	}
+}
+
+func matchTeamIDs(db *gorm.DB, matchID uint) ([]uint, error) {
+	var match models.Match
+	if err := db.First(&match, matchID).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, id := range []*uint{match.TeamAID, match.TeamBID} {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	return ids, nil
+}
+
+// teamManagerIDs lists the captains, vice-captains and managers of the teams
+func teamManagerIDs(db *gorm.DB, teamIDs []uint) ([]uint, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := db.Model(&models.TeamMember{}).Where("team_id IN ?", teamIDs).Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// teamPlayerUserIDs lists the player accounts of the teams' squads
+func teamPlayerUserIDs(db *gorm.DB, teamIDs []uint) ([]uint, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := db.Model(&models.User{}).Joins("JOIN players ON players.id = users.player_id").
+		Where("players.team_id IN ?", teamIDs).Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+// adminsWithPermission lists the admins holding a permission league-wide or in the tournament
+func adminsWithPermission(db *gorm.DB, permission string, tournamentID uint) ([]uint, error) {
+	q := db.Where("tournament_id IS NULL")
+	if tournamentID != 0 {
+		q = db.Where("tournament_id IS NULL OR tournament_id = ?", tournamentID)
+	}
+	var assignments []models.RoleAssignment
+	if err := q.Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, a := range assignments {
+		if roleGrants(a.Role, permission) {
+			ids = append(ids, a.UserID)
+		}
+	}
+	return ids, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
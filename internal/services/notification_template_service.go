@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownEvent    = errors.New("unknown event")
+	ErrInvalidTemplate = errors.New("invalid template")
+)
+
+// eventTemplate is the built-in message of an event and the placeholders it offers
+type eventTemplate struct {
+	Body      string
+	Variables []string
+}
+
+var defaultTemplates = map[string]eventTemplate{
+	EventMatchScheduled: {
+		Body:      "{team_a} vs {team_b} ({tournament}) is scheduled for {scheduled_at}.",
+		Variables: []string{"match_id", "team_a", "team_b", "tournament", "scheduled_at"},
+	},
+	EventResultSubmitted: {
+		Body:      "{submitted_by} reported the result {team_a} {score_a} - {score_b} {team_b}. Please confirm or dispute it.",
+		Variables: []string{"match_id", "team_a", "team_b", "tournament", "score_a", "score_b", "submitted_by"},
+	},
+	EventResultDisputed: {
+		Body:      "The result of {team_a} vs {team_b} ({tournament}) was disputed by {disputed_by}: {reason}",
+		Variables: []string{"match_id", "team_a", "team_b", "tournament", "score_a", "score_b", "disputed_by", "reason"},
+	},
+	EventPlayerSuspended: {
+		Body:      "{player} ({team}) has been suspended.",
+		Variables: []string{"player", "team"},
+	},
+	EventTeamRegistrationApproved: {
+		Body:      "{team} is registered for {tournament}.",
+		Variables: []string{"team", "tournament"},
+	},
+	EventBanApplied: {
+		Body:      "Your account has been banned until {expires}. Reason: {reason}",
+		Variables: []string{"reason", "expires"},
+	},
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// NotificationTemplateView is a template as shown to admins
+type NotificationTemplateView struct {
+	Event       string     `json:"event"`
+	Body        string     `json:"body"`
+	DefaultBody string     `json:"default_body"`
+	Variables   []string   `json:"variables"`
+	Customized  bool       `json:"customized"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type NotificationTemplateService struct {
+	db *gorm.DB
+}
+
+func NewNotificationTemplateService() *NotificationTemplateService {
+	return &NotificationTemplateService{
+		db: database.GetDB(),
+	}
+}
+
+// Templates lists every event's template, with admin overrides applied
+func (s *NotificationTemplateService) Templates() ([]NotificationTemplateView, error) {
+	var overrides []models.NotificationTemplate
+	if err := s.db.Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	byEvent := make(map[string]models.NotificationTemplate, len(overrides))
+	for _, o := range overrides {
+		byEvent[o.Event] = o
+	}
+
+	views := make([]NotificationTemplateView, 0, len(defaultTemplates))
+	for event, def := range defaultTemplates {
+		view := NotificationTemplateView{Event: event, Body: def.Body, DefaultBody: def.Body, Variables: def.Variables}
+		if o, ok := byEvent[event]; ok {
+			updatedAt := o.UpdatedAt
+			view.Body = o.Body
+			view.Customized = true
+			view.UpdatedAt = &updatedAt
+		}
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Event < views[j].Event })
+	return views, nil
+}
+
+// Update replaces an event's message. Only the event's placeholders are allowed.
+func (s *NotificationTemplateService) Update(event, body string, adminID uint) (*models.NotificationTemplate, error) {
+	def, ok := defaultTemplates[event]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidTemplate)
+	}
+	for _, m := range templatePlaceholder.FindAllStringSubmatch(body, -1) {
+		if !slices.Contains(def.Variables, m[1]) {
+			return nil, fmt.Errorf("%w: unknown placeholder {%s}", ErrInvalidTemplate, m[1])
+		}
+	}
+
+	template := models.NotificationTemplate{Event: event, Body: body, UpdatedByID: &adminID}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"body", "updated_by_id", "updated_at"}),
+	}).Create(&template).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("event = ?", event).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Reset drops an override, going back to the built-in message
+func (s *NotificationTemplateService) Reset(event string) error {
+	if _, ok := defaultTemplates[event]; !ok {
+		return ErrUnknownEvent
+	}
+	return s.db.Where("event = ?", event).Delete(&models.NotificationTemplate{}).Error
+}
+
+// Render builds the message for an event. Placeholders without a value are left empty.
+func (s *NotificationTemplateService) Render(event DomainEvent) (string, error) {
+	def, ok := defaultTemplates[event.Name]
+	if !ok {
+		return "", ErrUnknownEvent
+	}
+	body := def.Body
+
+	var override models.NotificationTemplate
+	err := s.db.Where("event = ?", event.Name).First(&override).Error
+	if err == nil {
+		body = override.Body
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return templatePlaceholder.ReplaceAllStringFunc(body, func(placeholder string) string {
+		return event.Data[strings.Trim(placeholder, "{}")]
+	}), nil
+}
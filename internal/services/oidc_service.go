@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/oidc"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrInvalidOIDCState  = errors.New("invalid or expired sign-in attempt")
+	ErrIdentityLinked    = errors.New("this identity is already linked to another account")
+	ErrProvisioningOff   = errors.New("no account is linked to this identity")
+	ErrAdminLinkRequired = errors.New("admin accounts must link identities while signed in")
+)
+
+// OIDCLoginStateTTL is how long the user has to finish signing in at the provider
+const OIDCLoginStateTTL = 10 * time.Minute
+
+var usernameDisallowedChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// OIDCService signs users in through OpenID Connect providers configured with
+// OIDC_PROVIDERS. Unknown identities are linked to an existing account with
+// the same verified email, or else provisioned as a new captain account
+// (disable with OIDC_AUTO_PROVISION=false).
+type OIDCService struct {
+	db        *gorm.DB
+	auth      *AuthService
+	providers map[string]*oidc.Provider
+}
+
+func NewOIDCService() *OIDCService {
+	return &OIDCService{
+		db:        database.GetDB(),
+		auth:      NewAuthService(),
+		providers: oidc.ProvidersFromEnv(),
+	}
+}
+
+// Providers lists the configured provider names
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Begin starts a sign-in (or, with linkUserID, an account link) and returns
+// the provider URL to send the user to, and the state. The caller binds the
+// state to the browser (a cookie) and hands it back to Complete.
+func (s *OIDCService) Begin(ctx context.Context, providerName string, linkUserID *uint, teamName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := utils.RandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	url, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Drop abandoned attempts while we're here
+	s.db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+
+	record := models.OIDCLoginState{
+		State:        state,
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		TeamName:     strings.TrimSpace(teamName),
+		ExpiresAt:    time.Now().Add(OIDCLoginStateTTL),
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return "", "", err
+	}
+	return url, state, nil
+}
+
+// OIDCResult is the outcome of a provider callback. Login is nil when the
+// callback linked an identity to a signed in user.
+type OIDCResult struct {
+	Login    *LoginResult
+	User     *models.User
+	Identity *models.UserIdentity
+	Created  bool // A new account was provisioned
+}
+
+// checkAttempt accepts an attempt before it expires, and only from whoever
+// started it. A sign-in has to come back to the browser that started it
+// (boundState is the state it kept). Linking is started by an app with the
+// user's token, which the browser finishing it doesn't share, so it has to be
+// completed by that same signed in user (userID) instead.
+func checkAttempt(attempt *models.OIDCLoginState, state, boundState string, userID *uint, now time.Time) error {
+	if attempt.LinkUserID != nil {
+		if userID == nil || *userID != *attempt.LinkUserID {
+			return ErrInvalidOIDCState
+		}
+	} else if boundState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(boundState)) != 1 {
+		return ErrInvalidOIDCState
+	}
+	if attempt.State != state || now.After(attempt.ExpiresAt) {
+		return ErrInvalidOIDCState
+	}
+	return nil
+}
+
+// Complete handles the provider callback: it checks the attempt was made by
+// the same browser (boundState) or, for linking, the same user (userID),
+// exchanges the code (with the PKCE verifier) and signs the matching user in.
+func (s *OIDCService) Complete(ctx context.Context, providerName, code, state, boundState string, userID *uint) (*OIDCResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	var attempt models.OIDCLoginState
+	if err := s.db.Where("state = ? AND provider = ?", state, providerName).First(&attempt).Error; err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	if err := checkAttempt(&attempt, state, boundState, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	// The state is single-use; of two callbacks racing with it, only the one
+	// that deletes it goes on
+	claim := s.db.Where("state = ?", state).Delete(&models.OIDCLoginState{})
+	if claim.Error != nil {
+		return nil, claim.Error
+	}
+	if claim.RowsAffected != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, attempt.CodeVerifier, attempt.Nonce)
+	if err != nil {
+		log.Printf("OIDC sign-in with %s failed: %v", providerName, err)
+		return nil, err
+	}
+
+	if attempt.LinkUserID != nil {
+		identity, err := s.link(*attempt.LinkUserID, providerName, claims)
+		if err != nil {
+			return nil, err
+		}
+		var user models.User
+		if err := s.db.First(&user, *attempt.LinkUserID).Error; err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: &user, Identity: identity}, nil
+	}
+
+	result := &OIDCResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		user, identity, created, err := s.findOrProvision(tx, providerName, claims, attempt.TeamName)
+		if err != nil {
+			return err
+		}
+		if err := CheckAccount(tx, user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(identity).Update("last_login_at", now).Error; err != nil {
+			return err
+		}
+		identity.LastLoginAt = &now
+
+		// Same second factor rules as a password login
+		login, err := s.auth.completeLogin(tx, user)
+		if err != nil {
+			return err
+		}
+		*result = OIDCResult{Login: login, User: user, Identity: identity, Created: created}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findOrProvision resolves the account for an identity: an existing link,
+// then an account with the same verified email, then a new captain account.
+func (s *OIDCService) findOrProvision(tx *gorm.DB, providerName string, claims *oidc.Claims, teamName string) (*models.User, *models.UserIdentity, bool, error) {
+	var identity models.UserIdentity
+	err := tx.Where("provider = ? AND subject = ?", providerName, claims.Subject).First(&identity).Error
+	if err == nil {
+		var user models.User
+		if err := tx.First(&user, identity.UserID).Error; err != nil {
+			return nil, nil, false, err
+		}
+		return &user, &identity, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, false, err
+	}
+
+	// Link to an existing account only on an email the provider vouches for
+	var user models.User
+	if claims.Email != "" && claims.EmailVerified && CheckEmail(&claims.Email) == nil {
+		err := tx.Where("email = ?", claims.Email).First(&user).Error
+		if err == nil {
+			if user.Role == "admin" {
+				return nil, nil, false, ErrAdminLinkRequired
+			}
+			identity, err := createIdentity(tx, user.ID, providerName, claims)
+			return &user, identity, false, err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, false, err
+		}
+	}
+
+	if strings.EqualFold(os.Getenv("OIDC_AUTO_PROVISION"), "false") {
+		return nil, nil, false, ErrProvisioningOff
+	}
+
+	created, err := s.provisionCaptain(tx, claims, teamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	newIdentity, err := createIdentity(tx, created.ID, providerName, claims)
+	return created, newIdentity, true, err
+}
+
+// provisionCaptain creates a captain account for a new identity, with a team
+// when one was named at sign-in. The random password is never handed out;
+// the user can set one through the password reset flow.
+func (s *OIDCService) provisionCaptain(tx *gorm.DB, claims *oidc.Claims, teamName string) (*models.User, error) {
+	username, err := uniqueUsername(tx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Username: username,
+		Password: hashedPassword,
+		Role:     "captain",
+		IsActive: true,
+	}
+	if claims.Email != "" && claims.EmailVerified {
+		var taken int64
+		if err := tx.Model(&models.User{}).Where("email = ?", claims.Email).Count(&taken).Error; err != nil {
+			return nil, err
+		}
+		if taken == 0 {
+			email := claims.Email
+			user.Email = &email
+		}
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	if teamName != "" {
+		team := models.Team{Name: teamName, CaptainID: user.ID}
+		if err := tx.Create(&team).Error; err != nil {
+			return nil, fmt.Errorf("team '%s' could not be created", teamName)
+		}
+		member := models.TeamMember{TeamID: team.ID, UserID: user.ID, Role: TeamRoleCaptain}
+		if err := tx.Create(&member).Error; err != nil {
+			return nil, err
+		}
+		user.TeamID = &team.ID
+		if err := tx.Model(&user).Update("team_id", team.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Provisioned captain '%s' from an OIDC sign-in", username)
+	return &user, nil
+}
+
+// uniqueUsername derives a free username from the identity's claims
+func uniqueUsername(tx *gorm.DB, claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" && claims.Email != "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	base = usernameDisallowedChars.ReplaceAllString(base, "")
+	if base == "" {
+		base = "captain"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	username := base
+	for i := 2; i < 100; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return username, nil
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	suffix, err := utils.RandomCode(6)
+	if err != nil {
+		return "", err
+	}
+	return base + "-" + strings.ToLower(suffix), nil
+}
+
+func createIdentity(tx *gorm.DB, userID uint, providerName string, claims *oidc.Claims) (*models.UserIdentity, error) {
+	identity := models.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := tx.Create(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// link attaches an identity to a signed in user
+func (s *OIDCService) link(userID uint, providerName string, claims *oidc.Claims) (*models.UserIdentity, error) {
+	var existing models.UserIdentity
+	err := s.db.Where("provider = ? AND subject = ?", providerName, claims.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityLinked
+		}
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return createIdentity(s.db, userID, providerName, claims)
+}
+
+// Identities lists the providers linked to a user
+func (s *OIDCService) Identities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Unlink removes one of the user's linked identities
+func (s *OIDCService) Unlink(userID, identityID uint) error {
+	var identity models.UserIdentity
+	if err := s.db.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+		return err
+	}
+	return s.db.Delete(&identity).Error
+}
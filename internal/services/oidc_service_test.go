@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+)
+
+func TestCheckAttempt(t *testing.T) {
+	now := time.Now()
+	attempt := &models.OIDCLoginState{State: "state-1", ExpiresAt: now.Add(OIDCLoginStateTTL)}
+	linker, other := uint(7), uint(8)
+	link := &models.OIDCLoginState{State: "state-1", LinkUserID: &linker, ExpiresAt: now.Add(OIDCLoginStateTTL)}
+
+	tests := []struct {
+		name       string
+		attempt    *models.OIDCLoginState
+		state      string
+		boundState string
+		userID     *uint
+		wantErr    bool
+	}{
+		{name: "same browser", attempt: attempt, state: "state-1", boundState: "state-1"},
+		{name: "state from another browser", attempt: attempt, state: "state-1", boundState: "state-2", wantErr: true},
+		{name: "no state cookie", attempt: attempt, state: "state-1", boundState: "", wantErr: true},
+		{name: "state of another attempt", attempt: attempt, state: "state-2", boundState: "state-2", wantErr: true},
+		{
+			name:       "expired attempt",
+			attempt:    &models.OIDCLoginState{State: "state-1", ExpiresAt: now.Add(-time.Second)},
+			state:      "state-1",
+			boundState: "state-1",
+			wantErr:    true,
+		},
+		{name: "link by the user who started it", attempt: link, state: "state-1", userID: &linker},
+		{name: "link by another user", attempt: link, state: "state-1", boundState: "state-1", userID: &other, wantErr: true},
+		{name: "link without a signed in user", attempt: link, state: "state-1", boundState: "state-1", wantErr: true},
+		{name: "sign-in completed as a link", attempt: attempt, state: "state-1", userID: &linker, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAttempt(tt.attempt, tt.state, tt.boundState, tt.userID, now)
+			if tt.wantErr && !errors.Is(err, ErrInvalidOIDCState) {
+				t.Errorf("got %v, want ErrInvalidOIDCState", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+// Permissions checked by the admin routes
+const (
+	PermUserView              = "user:view"
+	PermUserManage            = "user:manage"
+	PermUserBan               = "user:ban"
+	PermRoleManage            = "role:manage"
+	PermTeamManage            = "team:manage"
+	PermPlayerManage          = "player:manage"
+	PermPlayerBan             = "player:ban"
+	PermStaffManage           = "staff:manage"
+	PermTournamentCreate      = "tournament:create"
+	PermTournamentEdit        = "tournament:edit"
+	PermTournamentDelete      = "tournament:delete"
+	PermMatchSchedule         = "match:schedule"
+	PermMatchResolve          = "match:resolve"
+	PermSeasonManage          = "season:manage"
+	PermTransferReview        = "transfer:review"
+	PermTransferWindows       = "transfer:windows"
+	PermNotificationSend      = "notification:send"
+	PermNotificationTemplates = "notification:templates"
+	PermWebhookManage         = "webhook:manage"
+	PermDashboardView         = "dashboard:view"
+)
+
+const (
+	RoleSuperAdmin         = "super_admin"
+	RoleTournamentDirector = "tournament_director"
+	RoleScorekeeper        = "scorekeeper"
+	RoleModerator          = "moderator"
+)
+
+// RolePermissions maps each admin role to what it may do. super_admin may do everything.
+var RolePermissions = map[string][]string{
+	RoleSuperAdmin: nil,
+	RoleTournamentDirector: {
+		PermTournamentCreate, PermTournamentEdit, PermTournamentDelete,
+		PermMatchSchedule, PermMatchResolve,
+		PermTeamManage, PermSeasonManage, PermTransferReview, PermTransferWindows,
+		PermDashboardView,
+	},
+	RoleScorekeeper: {
+		PermMatchSchedule, PermMatchResolve, PermDashboardView,
+	},
+	RoleModerator: {
+		PermUserView, PermUserBan, PermPlayerBan, PermNotificationSend, PermNotificationTemplates, PermDashboardView,
+	},
+}
+
+var (
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrLastSuperAdmin    = errors.New("cannot remove the last league-wide super_admin")
+	ErrRoleNotAssignable = errors.New("roles can only be assigned to admin accounts")
+)
+
+// roleGrants reports whether a role includes the permission
+func roleGrants(role, permission string) bool {
+	if role == RoleSuperAdmin {
+		return true
+	}
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type PermissionService struct {
+	db *gorm.DB
+}
+
+func NewPermissionService() *PermissionService {
+	return &PermissionService{
+		db: database.GetDB(),
+	}
+}
+
+// HasPermission reports whether the user holds the permission league-wide or,
+// when tournamentID is set, through an assignment scoped to that tournament.
+func (s *PermissionService) HasPermission(userID uint, permission string, tournamentID *uint) (bool, error) {
+	q := s.db.Where("user_id = ?", userID)
+	if tournamentID != nil {
+		q = q.Where("tournament_id IS NULL OR tournament_id = ?", *tournamentID)
+	} else {
+		q = q.Where("tournament_id IS NULL")
+	}
+
+	var assignments []models.RoleAssignment
+	if err := q.Find(&assignments).Error; err != nil {
+		return false, err
+	}
+	for _, a := range assignments {
+		if roleGrants(a.Role, permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EffectivePermission is a permission held by a user and where it applies
+type EffectivePermission struct {
+	Permission   string `json:"permission"`
+	TournamentID *uint  `json:"tournament_id,omitempty"`
+}
+
+// Permissions lists everything the user may do, per scope
+func (s *PermissionService) Permissions(userID uint) ([]EffectivePermission, error) {
+	var assignments []models.RoleAssignment
+	if err := s.db.Where("user_id = ?", userID).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		perm       string
+		tournament uint
+	}
+	seen := make(map[key]bool)
+	perms := []EffectivePermission{}
+	for _, a := range assignments {
+		granted := RolePermissions[a.Role]
+		if a.Role == RoleSuperAdmin {
+			granted = AllPermissions()
+		}
+		for _, p := range granted {
+			k := key{perm: p}
+			if a.TournamentID != nil {
+				k.tournament = *a.TournamentID
+			}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			perms = append(perms, EffectivePermission{Permission: p, TournamentID: a.TournamentID})
+		}
+	}
+	return perms, nil
+}
+
+// AllPermissions lists every known permission, sorted
+func AllPermissions() []string {
+	return []string{
+		PermDashboardView,
+		PermMatchResolve, PermMatchSchedule,
+		PermNotificationSend, PermNotificationTemplates,
+		PermPlayerBan, PermPlayerManage,
+		PermRoleManage,
+		PermSeasonManage,
+		PermStaffManage,
+		PermTeamManage,
+		PermTournamentCreate, PermTournamentDelete, PermTournamentEdit,
+		PermTransferReview, PermTransferWindows,
+		PermUserBan, PermUserManage, PermUserView,
+		PermWebhookManage,
+	}
+}
+
+// Roles returns each role with its permissions, for the admin UI
+func (s *PermissionService) Roles() map[string][]string {
+	roles := make(map[string][]string, len(RolePermissions))
+	for role, perms := range RolePermissions {
+		if role == RoleSuperAdmin {
+			perms = AllPermissions()
+		}
+		sorted := append([]string(nil), perms...)
+		sort.Strings(sorted)
+		roles[role] = sorted
+	}
+	return roles
+}
+
+// Assignments lists role assignments, optionally for a single user
+func (s *PermissionService) Assignments(userID uint) ([]models.RoleAssignment, error) {
+	q := s.db.Preload("User")
+	if userID != 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+
+	var assignments []models.RoleAssignment
+	if err := q.Order("user_id, id").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// Assign grants a role to an admin account, league-wide or for one tournament.
+// Assigning a role the user already holds in that scope returns the existing assignment.
+func (s *PermissionService) Assign(userID uint, role string, tournamentID *uint, grantedByID *uint) (*models.RoleAssignment, error) {
+	if _, ok := RolePermissions[role]; !ok {
+		return nil, ErrUnknownRole
+	}
+
+	var assignment *models.RoleAssignment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		assignment, err = assignRole(tx, userID, role, tournamentID, grantedByID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// assignRole is Assign within the caller's transaction
+func assignRole(tx *gorm.DB, userID uint, role string, tournamentID *uint, grantedByID *uint) (*models.RoleAssignment, error) {
+	if _, ok := RolePermissions[role]; !ok {
+		return nil, ErrUnknownRole
+	}
+
+	var user models.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.Role != "admin" {
+		return nil, ErrRoleNotAssignable
+	}
+	if tournamentID != nil {
+		if err := tx.First(&models.Tournament{}, *tournamentID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var assignment models.RoleAssignment
+	q := tx.Where("user_id = ? AND role = ?", userID, role)
+	if tournamentID != nil {
+		q = q.Where("tournament_id = ?", *tournamentID)
+	} else {
+		q = q.Where("tournament_id IS NULL")
+	}
+	err := q.First(&assignment).Error
+	if err == nil {
+		return &assignment, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	assignment = models.RoleAssignment{
+		UserID:       userID,
+		Role:         role,
+		TournamentID: tournamentID,
+		GrantedByID:  grantedByID,
+	}
+	if err := tx.Create(&assignment).Error; err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// Revoke removes a role assignment. The last league-wide super_admin can't be
+// removed, so the league is never left without someone able to manage roles.
+func (s *PermissionService) Revoke(assignmentID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var assignment models.RoleAssignment
+		if err := tx.First(&assignment, assignmentID).Error; err != nil {
+			return err
+		}
+
+		if assignment.Role == RoleSuperAdmin && assignment.TournamentID == nil {
+			var others int64
+			if err := tx.Model(&models.RoleAssignment{}).
+				Where("role = ? AND tournament_id IS NULL AND id <> ?", RoleSuperAdmin, assignment.ID).
+				Count(&others).Error; err != nil {
+				return err
+			}
+			if others == 0 {
+				return ErrLastSuperAdmin
+			}
+		}
+
+		return tx.Delete(&assignment).Error
+	})
+}
+
+// RevokeUser drops every assignment of a user, e.g. when the account is deleted
+func (s *PermissionService) RevokeUser(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var held, others int64
+		if err := tx.Model(&models.RoleAssignment{}).
+			Where("role = ? AND tournament_id IS NULL AND user_id = ?", RoleSuperAdmin, userID).
+			Count(&held).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RoleAssignment{}).
+			Where("role = ? AND tournament_id IS NULL AND user_id <> ?", RoleSuperAdmin, userID).
+			Count(&others).Error; err != nil {
+			return err
+		}
+		if held > 0 && others == 0 {
+			return ErrLastSuperAdmin
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RoleAssignment{}).Error
+	})
+}
+
+// BackfillSuperAdmins makes every existing admin a super_admin while no role
+// has been assigned yet, so upgrading keeps admins' access unchanged.
+func (s *PermissionService) BackfillSuperAdmins() error {
+	var count int64
+	if err := s.db.Model(&models.RoleAssignment{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var admins []models.User
+	if err := s.db.Where("role = ?", "admin").Find(&admins).Error; err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if _, err := s.Assign(admin.ID, RoleSuperAdmin, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type PlayerService struct {
+	db *gorm.DB
+}
+
+func NewPlayerService() *PlayerService {
+	return &PlayerService{
+		db: database.GetDB(),
+	}
+}
+
+// onTeam reports whether a (possibly free agent) team pointer matches teamID
+func onTeam(playerTeamID *uint, teamID *uint) bool {
+	return playerTeamID != nil && teamID != nil && *playerTeamID == *teamID
+}
+
+// sameTeam is like onTeam but also treats two free agents as matching
+func sameTeam(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// assignTeam moves a player to a team (or to free agency when teamID is nil),
+// closing their current membership and opening a new one.
+func assignTeam(tx *gorm.DB, player *models.Player, teamID *uint) error {
+	if sameTeam(player.TeamID, teamID) {
+		return nil
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.TeamMembership{}).
+		Where("player_id = ? AND left_at IS NULL", player.ID).
+		Update("left_at", now).Error; err != nil {
+		return err
+	}
+
+	if teamID != nil {
+		var team models.Team
+		if err := tx.First(&team, *teamID).Error; err != nil {
+			return err
+		}
+		membership := models.TeamMembership{
+			PlayerID: player.ID,
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			JoinedAt: now,
+		}
+		if err := tx.Create(&membership).Error; err != nil {
+			return err
+		}
+	}
+
+	player.TeamID = teamID
+	return tx.Model(player).Update("team_id", teamID).Error
+}
+
+// CreatePlayer creates a player and opens a membership if they start on a team
+func (s *PlayerService) CreatePlayer(player *models.Player) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		teamID := player.TeamID
+		player.TeamID = nil
+		if err := tx.Create(player).Error; err != nil {
+			return err
+		}
+		return assignTeam(tx, player, teamID)
+	})
+}
+
+// ReleasePlayer turns a team's player into a free agent
+func (s *PlayerService) ReleasePlayer(playerID, teamID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.First(&player, playerID).Error; err != nil {
+			return err
+		}
+		if !onTeam(player.TeamID, &teamID) {
+			return errors.New("player not in team")
+		}
+		return assignTeam(tx, &player, nil)
+	})
+}
+
+// SetBanned bans or reinstates a player. A new ban is announced as a suspension.
+func (s *PlayerService) SetBanned(playerID uint, banned bool) (*models.Player, error) {
+	var player models.Player
+	if err := s.db.First(&player, playerID).Error; err != nil {
+		return nil, err
+	}
+
+	suspended := banned && !player.IsBanned
+	player.IsBanned = banned
+	event := DomainEvent{Name: EventPlayerSuspended, PlayerID: player.ID, Data: map[string]string{"player": player.Name}}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&player).Update("is_banned", banned).Error; err != nil {
+			return err
+		}
+		if !suspended {
+			return nil
+		}
+		if player.TeamID != nil {
+			var team models.Team
+			if err := tx.Select("id", "name").First(&team, *player.TeamID).Error; err == nil {
+				event.TeamID = team.ID
+				event.Data["team"] = team.Name
+			}
+		}
+		return enqueueWebhooks(tx, &event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if suspended {
+		Events().Publish(event)
+	}
+	return &player, nil
+}
+
+// DeleteTeam releases all of a team's players to free agency before deleting it,
+// so players and their history outlive the team.
+func (s *PlayerService) DeleteTeam(teamID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var players []models.Player
+		if err := tx.Where("team_id = ?", teamID).Find(&players).Error; err != nil {
+			return err
+		}
+		for i := range players {
+			if err := assignTeam(tx, &players[i], nil); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Team{}, teamID).Error
+	})
+}
+
+// playerInviteTTL is how long a captain's invite code stays valid
+const playerInviteTTL = 7 * 24 * time.Hour
+
+// CreateInvite issues a fresh invite code for a player of the team, replacing unclaimed ones
+func (s *PlayerService) CreateInvite(playerID, teamID, createdByID uint) (*models.PlayerInvite, error) {
+	var invite models.PlayerInvite
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.First(&player, playerID).Error; err != nil {
+			return err
+		}
+		if !onTeam(player.TeamID, &teamID) {
+			return errors.New("player not in team")
+		}
+
+		var linked int64
+		if err := tx.Model(&models.User{}).Where("player_id = ?", playerID).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return errors.New("player already has an account")
+		}
+
+		if err := tx.Where("player_id = ? AND claimed_at IS NULL", playerID).Delete(&models.PlayerInvite{}).Error; err != nil {
+			return err
+		}
+
+		code, err := utils.RandomCode(8)
+		if err != nil {
+			return err
+		}
+		invite = models.PlayerInvite{
+			Code:        code,
+			PlayerID:    playerID,
+			TeamID:      teamID,
+			CreatedByID: createdByID,
+			ExpiresAt:   time.Now().Add(playerInviteTTL),
+		}
+		return tx.Create(&invite).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// FreeAgents lists players without a team, optionally filtered by position
+func (s *PlayerService) FreeAgents(position string) ([]models.Player, error) {
+	q := s.db.Where("team_id IS NULL AND is_banned = ?", false)
+	if position != "" {
+		q = q.Where("position = ?", position)
+	}
+
+	var players []models.Player
+	if err := q.Order("name").Find(&players).Error; err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// Memberships returns a player's team history, most recent first
+func (s *PlayerService) Memberships(playerID uint) ([]models.TeamMembership, error) {
+	var memberships []models.TeamMembership
+	if err := s.db.Where("player_id = ?", playerID).Order("joined_at desc").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// BackfillMemberships opens a membership for players on a team that have none,
+// covering players created before memberships were tracked.
+func (s *PlayerService) BackfillMemberships() error {
+	var players []models.Player
+	if err := s.db.Where("team_id IS NOT NULL AND id NOT IN (?)",
+		s.db.Model(&models.TeamMembership{}).Select("player_id").Where("left_at IS NULL"),
+	).Find(&players).Error; err != nil {
+		return err
+	}
+
+	for _, player := range players {
+		var team models.Team
+		if err := s.db.First(&team, *player.TeamID).Error; err != nil {
+			continue
+		}
+		membership := models.TeamMembership{
+			PlayerID: player.ID,
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			JoinedAt: player.CreatedAt,
+		}
+		if err := s.db.Create(&membership).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
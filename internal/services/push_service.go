@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/push"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// pushBatch caps how many deliveries are sent per dispatcher pass
+const pushBatch = 500
+
+// pushWake lets newly queued pushes skip the wait for the next dispatcher tick
+var pushWake = make(chan struct{}, 1)
+
+var (
+	pushProvider     push.Provider
+	pushProviderOnce sync.Once
+)
+
+// PushProvider returns the provider pushes go out through, configured by
+// PUSH_DRIVER unless another one was plugged in. Nil means push is off.
+func PushProvider() push.Provider {
+	pushProviderOnce.Do(func() {
+		if pushProvider == nil {
+			pushProvider = push.NewFromEnv()
+		}
+	})
+	return pushProvider
+}
+
+// SetPushProvider plugs in another provider (e.g. FCM, or a fake in tests) at
+// startup, before the dispatcher runs
+func SetPushProvider(provider push.Provider) {
+	pushProvider = provider
+}
+
+// PushPolicy sets the retry backoff and the clean up of old tokens
+type PushPolicy struct {
+	MaxAttempts       int
+	BackoffBase       time.Duration // Wait after the first failure, doubled each time
+	BackoffMax        time.Duration
+	Lease             time.Duration // How long a claimed delivery is left to its sender before another instance retries it
+	TokenMaxAge       time.Duration // Devices that haven't registered again in this long are dropped
+	DeliveryRetention time.Duration // Sent and failed deliveries are kept this long
+}
+
+func pushPolicyFromEnv() PushPolicy {
+	return PushPolicy{
+		MaxAttempts:       utils.EnvInt("PUSH_MAX_ATTEMPTS", 5),
+		BackoffBase:       utils.EnvDuration("PUSH_BACKOFF_BASE", 30*time.Second),
+		BackoffMax:        utils.EnvDuration("PUSH_BACKOFF_MAX", time.Hour),
+		Lease:             utils.EnvDuration("PUSH_LEASE", 2*time.Minute),
+		TokenMaxAge:       utils.EnvDuration("PUSH_TOKEN_MAX_AGE", 60*24*time.Hour),
+		DeliveryRetention: utils.EnvDuration("PUSH_DELIVERY_RETENTION", 7*24*time.Hour),
+	}
+}
+
+type PushService struct {
+	db       *gorm.DB
+	provider push.Provider
+	policy   PushPolicy
+}
+
+func NewPushService() *PushService {
+	return &PushService{
+		db:       database.GetDB(),
+		provider: PushProvider(),
+		policy:   pushPolicyFromEnv(),
+	}
+}
+
+// Queue schedules a push of each notification to its user's devices, skipping
+// the categories the user turned push off for. With push off nothing is
+// queued, so no delivery claims to be sent.
+func (s *PushService) Queue(notifications []models.Notification) error {
+	if s.provider == nil || len(notifications) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		userIDs = append(userIDs, n.UserID)
+	}
+	userIDs = uniqueIDs(userIDs)
+
+	var devices []models.DeviceToken
+	if err := s.db.Where("user_id IN ?", userIDs).Find(&devices).Error; err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+
+	var muted []models.NotificationPreference
+	if err := s.db.Where("user_id IN ? AND push = ?", userIDs, false).Find(&muted).Error; err != nil {
+		return err
+	}
+
+	deliveries := pushDeliveries(notifications, devices, muted, time.Now())
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := s.db.CreateInBatches(&deliveries, pushBatch).Error; err != nil {
+		return err
+	}
+
+	wakeDispatcher(pushWake)
+	return nil
+}
+
+// pushDeliveries pairs each notification with its user's devices, leaving out
+// the categories muted by the user
+func pushDeliveries(notifications []models.Notification, devices []models.DeviceToken, muted []models.NotificationPreference, now time.Time) []models.PushDelivery {
+	byUser := make(map[uint][]models.DeviceToken)
+	for _, d := range devices {
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+	isMuted := make(map[uint]map[string]bool)
+	for _, m := range muted {
+		if m.Push {
+			continue
+		}
+		if isMuted[m.UserID] == nil {
+			isMuted[m.UserID] = make(map[string]bool)
+		}
+		isMuted[m.UserID][m.Category] = true
+	}
+
+	var deliveries []models.PushDelivery
+	for _, n := range notifications {
+		if isMuted[n.UserID][n.Category] {
+			continue
+		}
+		for _, d := range byUser[n.UserID] {
+			deliveries = append(deliveries, models.PushDelivery{
+				NotificationID: n.ID,
+				DeviceTokenID:  d.ID,
+				Status:         "pending",
+				NextAttemptAt:  now,
+			})
+		}
+	}
+	return deliveries
+}
+
+// pushMessage is the push shown for a notification
+func pushMessage(device models.DeviceToken, notification models.Notification) push.Message {
+	return push.Message{
+		Token:    device.Token,
+		Platform: device.Platform,
+		Title:    "LeagueMaster",
+		Body:     notification.Message,
+		Data: map[string]string{
+			"notification_id": strconv.FormatUint(uint64(notification.ID), 10),
+			"category":        notification.Category,
+		},
+	}
+}
+
+// pushOutcome is what becomes of a delivery after a send
+type pushOutcome struct {
+	Status    string // sent, failed, or still pending for a retry
+	DropToken bool   // The token is dead
+	RetryIn   time.Duration
+}
+
+// outcome decides a delivery's fate from the send error and the attempts so far
+func (p PushPolicy) outcome(err error, attempts int) pushOutcome {
+	switch {
+	case err == nil:
+		return pushOutcome{Status: "sent"}
+	case errors.Is(err, push.ErrInvalidToken):
+		return pushOutcome{Status: "failed", DropToken: true}
+	case attempts >= p.MaxAttempts:
+		return pushOutcome{Status: "failed"}
+	default:
+		return pushOutcome{Status: "pending", RetryIn: retryBackoff(p.BackoffBase, p.BackoffMax, attempts)}
+	}
+}
+
+// queue is the dispatcher of the push deliveries
+func (s *PushService) queue() *dispatcher[models.PushDelivery] {
+	return &dispatcher[models.PushDelivery]{
+		name:  "Push",
+		db:    s.db,
+		batch: pushBatch,
+		lease: s.policy.Lease,
+		wake:  pushWake,
+		claimKey: func(d *models.PushDelivery) (uint, *int) {
+			return d.ID, &d.Attempts
+		},
+		send: func(d *models.PushDelivery) bool {
+			s.deliver(d)
+			return true
+		},
+		prune: s.Prune,
+	}
+}
+
+// DispatchDue sends the deliveries whose time has come and returns how many it tried
+func (s *PushService) DispatchDue(now time.Time) (int, error) {
+	return s.queue().dispatchDue(now)
+}
+
+// deliver sends a claimed delivery and records the outcome
+func (s *PushService) deliver(d *models.PushDelivery) {
+	finish := func(status, reason string) {
+		s.db.Model(d).Updates(map[string]interface{}{"status": status, "last_error": reason})
+	}
+
+	var device models.DeviceToken
+	if err := s.db.First(&device, d.DeviceTokenID).Error; err != nil {
+		finish("failed", "device is no longer registered")
+		return
+	}
+	var notification models.Notification
+	if err := s.db.First(&notification, d.NotificationID).Error; err != nil {
+		finish("failed", "notification no longer exists")
+		return
+	}
+
+	err := s.provider.Send(pushMessage(device, notification))
+	outcome := s.policy.outcome(err, d.Attempts)
+	switch {
+	case outcome.Status == "sent":
+		finish("sent", "")
+	case outcome.DropToken:
+		// A dead token never comes back; drop it and everything queued for it
+		s.db.Delete(&device)
+		s.db.Model(&models.PushDelivery{}).Where("device_token_id = ? AND status = ?", device.ID, "pending").
+			Updates(map[string]interface{}{"status": "failed", "last_error": err.Error()})
+	case outcome.Status == "failed":
+		log.Printf("Push %d gave up after %d attempts: %v", d.ID, d.Attempts, err)
+		finish("failed", err.Error())
+	default:
+		s.db.Model(d).Updates(map[string]interface{}{
+			"last_error":      err.Error(),
+			"next_attempt_at": time.Now().Add(outcome.RetryIn),
+		})
+	}
+}
+
+// Prune drops devices that stopped registering and old delivery records
+func (s *PushService) Prune(now time.Time) error {
+	if err := s.db.Where("last_seen_at < ?", now.Add(-s.policy.TokenMaxAge)).Delete(&models.DeviceToken{}).Error; err != nil {
+		return err
+	}
+	return s.db.Where("status IN ? AND updated_at < ?", []string{"sent", "failed"}, now.Add(-s.policy.DeliveryRetention)).
+		Delete(&models.PushDelivery{}).Error
+}
+
+// RunDispatcher sends due pushes every interval, or right away when some are
+// queued, and prunes hourly. Meant to run in its own goroutine. It returns
+// right away when push is off.
+func (s *PushService) RunDispatcher(interval time.Duration) {
+	if s.provider == nil {
+		log.Println("Push notifications are off, set PUSH_DRIVER to turn them on")
+		return
+	}
+
+	s.queue().run(interval)
+}
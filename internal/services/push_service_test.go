@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/push"
+)
+
+var testPushPolicy = PushPolicy{MaxAttempts: 3, BackoffBase: time.Second, BackoffMax: 3 * time.Second}
+
+// sendUntilDone pushes a notification to a device the way the dispatcher
+// retries it, and returns the outcome of every attempt
+func sendUntilDone(provider push.Provider, device models.DeviceToken, n models.Notification) []pushOutcome {
+	var outcomes []pushOutcome
+	for attempts := 1; ; attempts++ {
+		outcome := testPushPolicy.outcome(provider.Send(pushMessage(device, n)), attempts)
+		outcomes = append(outcomes, outcome)
+		if outcome.Status != "pending" {
+			return outcomes
+		}
+	}
+}
+
+func TestPushRetriesUntilSent(t *testing.T) {
+	provider := push.NewFakeProvider()
+	provider.FailNext(2)
+	device := models.DeviceToken{ID: 1, UserID: 7, Token: "token-1", Platform: "ios"}
+	n := models.Notification{ID: 42, UserID: 7, Category: "match", Message: "Kick-off in an hour"}
+
+	outcomes := sendUntilDone(provider, device, n)
+	if len(outcomes) != 3 || outcomes[2].Status != "sent" {
+		t.Fatalf("got %+v, want two retries then sent", outcomes)
+	}
+	if outcomes[0].RetryIn != time.Second || outcomes[1].RetryIn != 2*time.Second {
+		t.Errorf("backoff %v, %v, want 1s, 2s", outcomes[0].RetryIn, outcomes[1].RetryIn)
+	}
+
+	sent := provider.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("%d messages sent, want 1", len(sent))
+	}
+	if sent[0].Token != "token-1" || sent[0].Body != n.Message || sent[0].Data["notification_id"] != "42" || sent[0].Data["category"] != "match" {
+		t.Errorf("unexpected message %+v", sent[0])
+	}
+}
+
+func TestPushGivesUp(t *testing.T) {
+	provider := push.NewFakeProvider()
+	provider.FailNext(10)
+	device := models.DeviceToken{ID: 1, UserID: 7, Token: "token-1"}
+
+	outcomes := sendUntilDone(provider, device, models.Notification{ID: 1, UserID: 7})
+	last := outcomes[len(outcomes)-1]
+	if len(outcomes) != testPushPolicy.MaxAttempts || last.Status != "failed" || last.DropToken {
+		t.Fatalf("got %+v, want failed after %d attempts", outcomes, testPushPolicy.MaxAttempts)
+	}
+	if len(provider.Sent()) != 0 {
+		t.Error("a message was sent")
+	}
+}
+
+func TestPushDropsInvalidToken(t *testing.T) {
+	provider := push.NewFakeProvider()
+	provider.Invalidate("token-1")
+	device := models.DeviceToken{ID: 1, UserID: 7, Token: "token-1"}
+
+	outcomes := sendUntilDone(provider, device, models.Notification{ID: 1, UserID: 7})
+	if len(outcomes) != 1 || outcomes[0].Status != "failed" || !outcomes[0].DropToken {
+		t.Fatalf("got %+v, want the token dropped on the first attempt", outcomes)
+	}
+}
+
+func TestPushDeliveriesRespectPreferences(t *testing.T) {
+	now := time.Now()
+	devices := []models.DeviceToken{
+		{ID: 1, UserID: 7, Token: "phone"},
+		{ID: 2, UserID: 7, Token: "tablet"},
+		{ID: 3, UserID: 8, Token: "phone-8"},
+	}
+	muted := []models.NotificationPreference{
+		{UserID: 7, Category: "transfer", Push: false},
+		{UserID: 8, Category: "match", Push: true},
+	}
+	notifications := []models.Notification{
+		{ID: 10, UserID: 7, Category: "match"},
+		{ID: 11, UserID: 7, Category: "transfer"},
+		{ID: 12, UserID: 8, Category: "match"},
+		{ID: 13, UserID: 9, Category: "match"},
+	}
+
+	got := make(map[[2]uint]bool)
+	for _, d := range pushDeliveries(notifications, devices, muted, now) {
+		if d.Status != "pending" || !d.NextAttemptAt.Equal(now) {
+			t.Errorf("delivery %+v not pending now", d)
+		}
+		got[[2]uint{d.NotificationID, d.DeviceTokenID}] = true
+	}
+	want := map[[2]uint]bool{{10, 1}: true, {10, 2}: true, {12, 3}: true}
+	if len(got) != len(want) {
+		t.Fatalf("got deliveries %v, want %v", got, want)
+	}
+	for k := range want {
+		if !got[k] {
+			t.Errorf("missing delivery of notification %d to device %d", k[0], k[1])
+		}
+	}
+}
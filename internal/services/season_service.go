@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSeasonArchived = errors.New("season is archived")
+
+type SeasonService struct {
+	db *gorm.DB
+}
+
+func NewSeasonService() *SeasonService {
+	return &SeasonService{
+		db: database.GetDB(),
+	}
+}
+
+// SeasonHistory is one season's entry in a player's history
+type SeasonHistory struct {
+	SeasonID   uint                        `json:"season_id"`
+	SeasonName string                      `json:"season_name"`
+	TeamID     uint                        `json:"team_id"`
+	TeamName   string                      `json:"team_name"`
+	Stats      []models.ArchivedPlayerStat `json:"stats"`
+}
+
+// SnapshotSquads replaces the season's squad snapshot with the current rosters
+func (s *SeasonService) SnapshotSquads(seasonID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var season models.Season
+		if err := tx.First(&season, seasonID).Error; err != nil {
+			return err
+		}
+		if season.Status == "archived" {
+			return ErrSeasonArchived
+		}
+		return snapshotSquads(tx, seasonID)
+	})
+}
+
+func snapshotSquads(tx *gorm.DB, seasonID uint) error {
+	if err := tx.Where("season_id = ?", seasonID).Delete(&models.SquadSnapshot{}).Error; err != nil {
+		return err
+	}
+
+	var teams []models.Team
+	if err := tx.Preload("Players").Find(&teams).Error; err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		for _, player := range team.Players {
+			snapshot := models.SquadSnapshot{
+				SeasonID:     seasonID,
+				TeamID:       team.ID,
+				TeamName:     team.Name,
+				PlayerID:     player.ID,
+				PlayerName:   player.Name,
+				JerseyNumber: player.JerseyNumber,
+				Position:     player.Position,
+			}
+			if err := tx.Create(&snapshot).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ArchiveSeason freezes standings and player stats for every tournament in the season.
+// Squads are snapshotted first if that hasn't been done yet.
+func (s *SeasonService) ArchiveSeason(seasonID uint) (*models.Season, error) {
+	var season models.Season
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Row lock, so a second archive waits and then finds it archived
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&season, seasonID).Error; err != nil {
+			return err
+		}
+		if season.Status == "archived" {
+			return ErrSeasonArchived
+		}
+		if err := tx.Where("season_id = ?", seasonID).Find(&season.Tournaments).Error; err != nil {
+			return err
+		}
+
+		var snapshotCount int64
+		if err := tx.Model(&models.SquadSnapshot{}).Where("season_id = ?", seasonID).Count(&snapshotCount).Error; err != nil {
+			return err
+		}
+		if snapshotCount == 0 {
+			if err := snapshotSquads(tx, seasonID); err != nil {
+				return err
+			}
+		}
+
+		// Team names as of the snapshot, for teams renamed or deleted since
+		var snapshots []models.SquadSnapshot
+		if err := tx.Where("season_id = ?", seasonID).Find(&snapshots).Error; err != nil {
+			return err
+		}
+		teamNames := make(map[uint]string)
+		for _, snap := range snapshots {
+			teamNames[snap.TeamID] = snap.TeamName
+		}
+		var teams []models.Team
+		if err := tx.Find(&teams).Error; err != nil {
+			return err
+		}
+		for _, team := range teams {
+			if _, ok := teamNames[team.ID]; !ok {
+				teamNames[team.ID] = team.Name
+			}
+		}
+
+		stats := &StatsService{db: tx}
+		for _, tournament := range season.Tournaments {
+			if err := archiveStandings(tx, seasonID, tournament, teamNames); err != nil {
+				return err
+			}
+
+			// One line per team the player represented, so what they did
+			// before a mid-season transfer stays with their old team
+			tournamentID := tournament.ID
+			playerStats, err := stats.PlayerTeamStats(StatsFilter{TournamentID: &tournamentID})
+			if err != nil {
+				return err
+			}
+			for _, stat := range playerStats {
+				archived := models.ArchivedPlayerStat{
+					SeasonID:       seasonID,
+					TournamentID:   tournament.ID,
+					TournamentName: tournament.Name,
+					PlayerID:       stat.PlayerID,
+					PlayerName:     stat.PlayerName,
+					TeamID:         stat.TeamID,
+					TeamName:       teamNames[stat.TeamID],
+					Appearances:    stat.Appearances,
+					Goals:          stat.Goals,
+					Assists:        stat.Assists,
+					YellowCards:    stat.YellowCards,
+					RedCards:       stat.RedCards,
+					CleanSheets:    stat.CleanSheets,
+				}
+				if err := tx.Create(&archived).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		season.Status = "archived"
+		season.ArchivedAt = &now
+		return tx.Omit("Tournaments").Save(&season).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// archiveStandings copies a tournament's standings, naming the teams as of
+// the squad snapshot
+func archiveStandings(tx *gorm.DB, seasonID uint, tournament models.Tournament, teamNames map[uint]string) error {
+	var standings []models.Standing
+	if err := tx.Where("tournament_id = ?", tournament.ID).Order("points desc, goals_for desc").Find(&standings).Error; err != nil {
+		return err
+	}
+
+	for i, st := range standings {
+		archived := models.ArchivedStanding{
+			SeasonID:       seasonID,
+			TournamentID:   tournament.ID,
+			TournamentName: tournament.Name,
+			TeamID:         st.TeamID,
+			TeamName:       teamNames[st.TeamID],
+			Rank:           i + 1,
+			Points:         st.Points,
+			Wins:           st.Wins,
+			Losses:         st.Losses,
+			Draws:          st.Draws,
+			GoalsFor:       st.GoalsFor,
+			GoalsAgainst:   st.GoalsAgainst,
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSeason removes a season that hasn't been archived and detaches its tournaments
+func (s *SeasonService) DeleteSeason(seasonID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var season models.Season
+		if err := tx.First(&season, seasonID).Error; err != nil {
+			return err
+		}
+		if season.Status == "archived" {
+			return ErrSeasonArchived
+		}
+
+		if err := tx.Model(&models.Tournament{}).Where("season_id = ?", seasonID).Update("season_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("season_id = ?", seasonID).Delete(&models.SquadSnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&season).Error
+	})
+}
+
+// PlayerHistory lists the teams and archived stats of a player season by season.
+// It only reads snapshot tables, so it works for players that were since deleted.
+func (s *SeasonService) PlayerHistory(playerID uint) ([]SeasonHistory, error) {
+	var snapshots []models.SquadSnapshot
+	if err := s.db.Where("player_id = ?", playerID).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	var stats []models.ArchivedPlayerStat
+	if err := s.db.Where("player_id = ?", playerID).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uint]*SeasonHistory)
+	for _, snap := range snapshots {
+		entries[snap.SeasonID] = &SeasonHistory{SeasonID: snap.SeasonID, TeamID: snap.TeamID, TeamName: snap.TeamName, Stats: []models.ArchivedPlayerStat{}}
+	}
+	for _, stat := range stats {
+		if _, ok := entries[stat.SeasonID]; !ok {
+			entries[stat.SeasonID] = &SeasonHistory{SeasonID: stat.SeasonID, TeamID: stat.TeamID, TeamName: stat.TeamName, Stats: []models.ArchivedPlayerStat{}}
+		}
+		entries[stat.SeasonID].Stats = append(entries[stat.SeasonID].Stats, stat)
+	}
+
+	if len(entries) == 0 {
+		return []SeasonHistory{}, nil
+	}
+
+	ids := make([]uint, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	var seasons []models.Season
+	if err := s.db.Where("id IN ?", ids).Find(&seasons).Error; err != nil {
+		return nil, err
+	}
+	for _, season := range seasons {
+		entries[season.ID].SeasonName = season.Name
+	}
+
+	history := make([]SeasonHistory, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, *entry)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].SeasonID < history[j].SeasonID })
+	return history, nil
+}
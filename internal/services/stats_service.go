@@ -0,0 +1,589 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+// StatsFilter narrows stats down to a tournament and/or a date range.
+// Dates are matched against the match kickoff (falling back to creation time).
+type StatsFilter struct {
+	TournamentID *uint
+	From         *time.Time
+	To           *time.Time
+}
+
+type PlayerStat struct {
+	PlayerID    uint   `json:"player_id"`
+	PlayerName  string `json:"player_name"`
+	TeamID      uint   `json:"team_id"`
+	Position    string `json:"position"`
+	Appearances int    `json:"appearances"`
+	Goals       int    `json:"goals"`
+	Assists     int    `json:"assists"`
+	YellowCards int    `json:"yellow_cards"`
+	RedCards    int    `json:"red_cards"`
+	CleanSheets int    `json:"clean_sheets"`
+}
+
+type TeamFairPlay struct {
+	TeamID      uint   `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	YellowCards int    `json:"yellow_cards"`
+	RedCards    int    `json:"red_cards"`
+	Points      int    `json:"points"` // Lower is better
+}
+
+// Fair play points per card
+const (
+	fairPlayYellowPoints = 1
+	fairPlayRedPoints    = 3
+)
+
+type StatsService struct {
+	db *gorm.DB
+}
+
+func NewStatsService() *StatsService {
+	return &StatsService{
+		db: database.GetDB(),
+	}
+}
+
+// scopeMatches applies the filter to a query that has the matches table available
+func scopeMatches(q *gorm.DB, filter StatsFilter) *gorm.DB {
+	if filter.TournamentID != nil {
+		q = q.Where("matches.tournament_id = ?", *filter.TournamentID)
+	}
+	if filter.From != nil {
+		q = q.Where("COALESCE(matches.scheduled_at, matches.created_at) >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("COALESCE(matches.scheduled_at, matches.created_at) <= ?", *filter.To)
+	}
+	return q
+}
+
+func isGoalkeeper(position string) bool {
+	switch strings.ToLower(strings.TrimSpace(position)) {
+	case "gk", "goalkeeper", "keeper":
+		return true
+	}
+	return false
+}
+
+// PlayerStats derives per-player stats from match events and lineups.
+// TeamID is the player's current team.
+func (s *StatsService) PlayerStats(filter StatsFilter) ([]PlayerStat, error) {
+	byTeam, players, err := s.playerTeamStats(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(byTeam) == 0 {
+		return []PlayerStat{}, nil
+	}
+
+	stats := make(map[uint]*PlayerStat)
+	for key, st := range byTeam {
+		stat, ok := stats[key.playerID]
+		if !ok {
+			stat = &PlayerStat{PlayerID: key.playerID}
+			if p, ok := players[key.playerID]; ok {
+				stat.PlayerName = p.Name
+				if p.TeamID != nil {
+					stat.TeamID = *p.TeamID
+				}
+				stat.Position = p.Position
+			}
+			stats[key.playerID] = stat
+		}
+		stat.Appearances += st.Appearances
+		stat.Goals += st.Goals
+		stat.Assists += st.Assists
+		stat.YellowCards += st.YellowCards
+		stat.RedCards += st.RedCards
+		stat.CleanSheets += st.CleanSheets
+	}
+
+	result := make([]PlayerStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PlayerID < result[j].PlayerID })
+	return result, nil
+}
+
+// PlayerTeamStats is PlayerStats split by the team the player represented, so
+// a player transferred mid-season has a line per team. TeamID is that team.
+func (s *StatsService) PlayerTeamStats(filter StatsFilter) ([]PlayerStat, error) {
+	byTeam, _, err := s.playerTeamStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PlayerStat, 0, len(byTeam))
+	for _, stat := range byTeam {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PlayerID != result[j].PlayerID {
+			return result[i].PlayerID < result[j].PlayerID
+		}
+		return result[i].TeamID < result[j].TeamID
+	})
+	return result, nil
+}
+
+type playerTeam struct{ playerID, teamID uint }
+
+// playerTeamStats tallies the stats per player and team represented. Events
+// and lineups that predate team attribution count for the player's current team.
+func (s *StatsService) playerTeamStats(filter StatsFilter) (map[playerTeam]*PlayerStat, map[uint]models.Player, error) {
+	var events []models.MatchEvent
+	q := scopeMatches(s.db.Joins("JOIN matches ON matches.id = match_events.match_id"), filter)
+	if err := q.Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var appearances []models.MatchAppearance
+	q = scopeMatches(s.db.Joins("JOIN matches ON matches.id = match_appearances.match_id"), filter)
+	if err := q.Find(&appearances).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var ids []uint
+	for _, a := range appearances {
+		ids = append(ids, a.PlayerID)
+	}
+	for _, e := range events {
+		ids = append(ids, e.PlayerID)
+		if e.AssistPlayerID != nil {
+			ids = append(ids, *e.AssistPlayerID)
+		}
+	}
+	players := make(map[uint]models.Player)
+	if len(ids) == 0 {
+		return map[playerTeam]*PlayerStat{}, players, nil
+	}
+	var found []models.Player
+	if err := s.db.Where("id IN ?", uniqueIDs(ids)).Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, p := range found {
+		players[p.ID] = p
+	}
+	teamOf := func(playerID, teamID uint) uint {
+		if teamID == 0 && players[playerID].TeamID != nil {
+			return *players[playerID].TeamID
+		}
+		return teamID
+	}
+
+	stats := make(map[playerTeam]*PlayerStat)
+	get := func(playerID, teamID uint) *PlayerStat {
+		key := playerTeam{playerID, teamID}
+		if _, ok := stats[key]; !ok {
+			p := players[playerID]
+			stats[key] = &PlayerStat{PlayerID: playerID, PlayerName: p.Name, TeamID: teamID, Position: p.Position}
+		}
+		return stats[key]
+	}
+
+	// A player appeared if they were in the lineup or involved in an event.
+	// The value is the team they represented.
+	type matchPlayer struct{ matchID, playerID uint }
+	appeared := make(map[matchPlayer]uint)
+	represent := func(mp matchPlayer, teamID uint) {
+		if appeared[mp] == 0 {
+			appeared[mp] = teamOf(mp.playerID, teamID)
+		}
+	}
+	for _, a := range appearances {
+		represent(matchPlayer{a.MatchID, a.PlayerID}, a.TeamID)
+	}
+
+	for _, e := range events {
+		represent(matchPlayer{e.MatchID, e.PlayerID}, e.TeamID)
+		stat := get(e.PlayerID, teamOf(e.PlayerID, e.TeamID))
+		switch e.EventType {
+		case "goal":
+			stat.Goals++
+			if e.AssistPlayerID != nil {
+				represent(matchPlayer{e.MatchID, *e.AssistPlayerID}, e.TeamID)
+				get(*e.AssistPlayerID, teamOf(*e.AssistPlayerID, e.TeamID)).Assists++
+			}
+		case "card_yellow":
+			stat.YellowCards++
+		case "card_red":
+			stat.RedCards++
+		}
+	}
+
+	byMatch := make(map[uint][]matchPlayer)
+	for mp, teamID := range appeared {
+		get(mp.playerID, teamID).Appearances++
+		byMatch[mp.matchID] = append(byMatch[mp.matchID], mp)
+	}
+
+	// Clean sheets: goalkeepers who appeared in a completed match without conceding
+	var matches []models.Match
+	q = scopeMatches(s.db.Model(&models.Match{}), filter).Where("status = ?", "completed")
+	if err := q.Find(&matches).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, m := range matches {
+		if m.TeamAID == nil || m.TeamBID == nil {
+			continue
+		}
+		for _, mp := range byMatch[m.ID] {
+			teamID := appeared[mp]
+			stat := get(mp.playerID, teamID)
+			if !isGoalkeeper(stat.Position) {
+				continue
+			}
+			if (teamID == *m.TeamAID && m.ScoreB == 0) || (teamID == *m.TeamBID && m.ScoreA == 0) {
+				stat.CleanSheets++
+			}
+		}
+	}
+	return stats, players, nil
+}
+
+// PlayerStatsFor returns the stats of a single player (zeroes if they have none).
+// TeamID is the current team, 0 for free agents.
+func (s *StatsService) PlayerStatsFor(playerID uint, filter StatsFilter) (*PlayerStat, error) {
+	var player models.Player
+	if err := s.db.First(&player, playerID).Error; err != nil {
+		return nil, err
+	}
+
+	all, err := s.PlayerStats(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range all {
+		if stat.PlayerID == playerID {
+			return &stat, nil
+		}
+	}
+	stat := &PlayerStat{PlayerID: player.ID, PlayerName: player.Name, Position: player.Position}
+	if player.TeamID != nil {
+		stat.TeamID = *player.TeamID
+	}
+	return stat, nil
+}
+
+// GoldenBoot ranks players by goals, then assists, then fewest appearances
+func (s *StatsService) GoldenBoot(filter StatsFilter, limit int) ([]PlayerStat, error) {
+	return s.leaderboard(filter, limit, func(a, b PlayerStat) bool {
+		if a.Goals != b.Goals {
+			return a.Goals > b.Goals
+		}
+		if a.Assists != b.Assists {
+			return a.Assists > b.Assists
+		}
+		return a.Appearances < b.Appearances
+	}, func(p PlayerStat) bool { return p.Goals > 0 })
+}
+
+// TopAssists ranks players by assists, then goals
+func (s *StatsService) TopAssists(filter StatsFilter, limit int) ([]PlayerStat, error) {
+	return s.leaderboard(filter, limit, func(a, b PlayerStat) bool {
+		if a.Assists != b.Assists {
+			return a.Assists > b.Assists
+		}
+		if a.Goals != b.Goals {
+			return a.Goals > b.Goals
+		}
+		return a.Appearances < b.Appearances
+	}, func(p PlayerStat) bool { return p.Assists > 0 })
+}
+
+func (s *StatsService) leaderboard(filter StatsFilter, limit int, less func(a, b PlayerStat) bool, keep func(PlayerStat) bool) ([]PlayerStat, error) {
+	all, err := s.PlayerStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	board := make([]PlayerStat, 0, len(all))
+	for _, stat := range all {
+		if keep(stat) {
+			board = append(board, stat)
+		}
+	}
+	sort.SliceStable(board, func(i, j int) bool { return less(board[i], board[j]) })
+
+	if limit > 0 && len(board) > limit {
+		board = board[:limit]
+	}
+	return board, nil
+}
+
+// FairPlay ranks teams by disciplinary points (fewest first). Cards count
+// against the team the player represented at the time, not their current one.
+func (s *StatsService) FairPlay(filter StatsFilter, limit int) ([]TeamFairPlay, error) {
+	var events []models.MatchEvent
+	q := scopeMatches(s.db.Joins("JOIN matches ON matches.id = match_events.match_id"), filter).
+		Where("match_events.event_type IN ?", []string{"card_yellow", "card_red"})
+	if err := q.Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	// Older events predate team attribution, fall back to the player's team
+	var missing []uint
+	for _, e := range events {
+		if e.TeamID == 0 {
+			missing = append(missing, e.PlayerID)
+		}
+	}
+	currentTeam := make(map[uint]uint)
+	if len(missing) > 0 {
+		var players []models.Player
+		if err := s.db.Where("id IN ?", missing).Find(&players).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range players {
+			if p.TeamID != nil {
+				currentTeam[p.ID] = *p.TeamID
+			}
+		}
+	}
+
+	teams := make(map[uint]*TeamFairPlay)
+	for _, e := range events {
+		teamID := e.TeamID
+		if teamID == 0 {
+			teamID = currentTeam[e.PlayerID]
+		}
+		if teamID == 0 {
+			continue
+		}
+		if _, ok := teams[teamID]; !ok {
+			teams[teamID] = &TeamFairPlay{TeamID: teamID}
+		}
+		team := teams[teamID]
+		if e.EventType == "card_red" {
+			team.RedCards++
+			team.Points += fairPlayRedPoints
+		} else {
+			team.YellowCards++
+			team.Points += fairPlayYellowPoints
+		}
+	}
+
+	ids := make([]uint, 0, len(teams))
+	for id := range teams {
+		ids = append(ids, id)
+	}
+	if len(ids) > 0 {
+		var rows []models.Team
+		if err := s.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, t := range rows {
+			teams[t.ID].TeamName = t.Name
+		}
+	}
+
+	board := make([]TeamFairPlay, 0, len(teams))
+	for _, team := range teams {
+		board = append(board, *team)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Points != board[j].Points {
+			return board[i].Points < board[j].Points
+		}
+		return board[i].TeamID < board[j].TeamID
+	})
+
+	if limit > 0 && len(board) > limit {
+		board = board[:limit]
+	}
+	return board, nil
+}
+
+type MatchSummary struct {
+	MatchID      uint       `json:"match_id"`
+	TournamentID uint       `json:"tournament_id"`
+	OpponentID   uint       `json:"opponent_id"`
+	GoalsFor     int        `json:"goals_for"`
+	GoalsAgainst int        `json:"goals_against"`
+	PlayedAt     *time.Time `json:"played_at,omitempty"`
+}
+
+type Streak struct {
+	Result string `json:"result"` // W, D or L
+	Count  int    `json:"count"`
+}
+
+type TeamStats struct {
+	TeamID         uint          `json:"team_id"`
+	TeamName       string        `json:"team_name"`
+	Played         int           `json:"played"`
+	Wins           int           `json:"wins"`
+	Draws          int           `json:"draws"`
+	Losses         int           `json:"losses"`
+	GoalsFor       int           `json:"goals_for"`
+	GoalsAgainst   int           `json:"goals_against"`
+	GoalDifference int           `json:"goal_difference"`
+	BiggestWin     *MatchSummary `json:"biggest_win"`
+	CurrentStreak  *Streak       `json:"current_streak"`
+	Form           []string      `json:"form"` // Last five results, most recent first
+}
+
+type HeadToHead struct {
+	TeamAID    uint           `json:"team_a_id"`
+	TeamBID    uint           `json:"team_b_id"`
+	Played     int            `json:"played"`
+	TeamAWins  int            `json:"team_a_wins"`
+	TeamBWins  int            `json:"team_b_wins"`
+	Draws      int            `json:"draws"`
+	TeamAGoals int            `json:"team_a_goals"`
+	TeamBGoals int            `json:"team_b_goals"`
+	Matches    []models.Match `json:"matches"`
+}
+
+// matchPlayedAt is the kickoff time if known, otherwise when the match was created
+func matchPlayedAt(m models.Match) time.Time {
+	if m.ScheduledAt != nil {
+		return *m.ScheduledAt
+	}
+	return m.CreatedAt
+}
+
+// goalsFor returns (scored, conceded) from the given team's perspective
+func goalsFor(m models.Match, teamID uint) (int, int) {
+	if m.TeamAID != nil && *m.TeamAID == teamID {
+		return m.ScoreA, m.ScoreB
+	}
+	return m.ScoreB, m.ScoreA
+}
+
+// completedMatches runs the query for completed matches and sorts them oldest first
+func (s *StatsService) completedMatches(q *gorm.DB) ([]models.Match, error) {
+	var matches []models.Match
+	if err := q.Where("status = ?", "completed").Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		ti, tj := matchPlayedAt(matches[i]), matchPlayedAt(matches[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches, nil
+}
+
+// TeamStats aggregates a team's record across every tournament
+func (s *StatsService) TeamStats(teamID uint) (*TeamStats, error) {
+	var team models.Team
+	if err := s.db.First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+
+	matches, err := s.completedMatches(s.db.Where("team_a_id = ? OR team_b_id = ?", teamID, teamID))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &TeamStats{TeamID: team.ID, TeamName: team.Name, Form: []string{}}
+	results := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		scored, conceded := goalsFor(m, teamID)
+		stats.Played++
+		stats.GoalsFor += scored
+		stats.GoalsAgainst += conceded
+
+		switch {
+		case scored > conceded:
+			stats.Wins++
+			results = append(results, "W")
+
+			margin := scored - conceded
+			best := stats.BiggestWin
+			if best == nil || margin > best.GoalsFor-best.GoalsAgainst || (margin == best.GoalsFor-best.GoalsAgainst && scored > best.GoalsFor) {
+				playedAt := matchPlayedAt(m)
+				opponent := m.TeamAID
+				if m.TeamAID != nil && *m.TeamAID == teamID {
+					opponent = m.TeamBID
+				}
+				summary := &MatchSummary{
+					MatchID:      m.ID,
+					TournamentID: m.TournamentID,
+					GoalsFor:     scored,
+					GoalsAgainst: conceded,
+					PlayedAt:     &playedAt,
+				}
+				if opponent != nil {
+					summary.OpponentID = *opponent
+				}
+				stats.BiggestWin = summary
+			}
+		case scored == conceded:
+			stats.Draws++
+			results = append(results, "D")
+		default:
+			stats.Losses++
+			results = append(results, "L")
+		}
+	}
+	stats.GoalDifference = stats.GoalsFor - stats.GoalsAgainst
+
+	// Walk backwards from the most recent result
+	for i := len(results) - 1; i >= 0; i-- {
+		if stats.CurrentStreak == nil {
+			stats.CurrentStreak = &Streak{Result: results[i]}
+		}
+		if results[i] != stats.CurrentStreak.Result {
+			break
+		}
+		stats.CurrentStreak.Count++
+	}
+	for i := len(results) - 1; i >= 0 && len(stats.Form) < 5; i-- {
+		stats.Form = append(stats.Form, results[i])
+	}
+
+	return stats, nil
+}
+
+// HeadToHead returns every completed match between two teams with aggregated totals
+func (s *StatsService) HeadToHead(teamAID, teamBID uint) (*HeadToHead, error) {
+	for _, id := range []uint{teamAID, teamBID} {
+		if err := s.db.First(&models.Team{}, id).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	q := s.db.Preload("TeamA").Preload("TeamB").
+		Where("(team_a_id = ? AND team_b_id = ?) OR (team_a_id = ? AND team_b_id = ?)", teamAID, teamBID, teamBID, teamAID)
+	matches, err := s.completedMatches(q)
+	if err != nil {
+		return nil, err
+	}
+
+	h2h := &HeadToHead{TeamAID: teamAID, TeamBID: teamBID, Matches: matches}
+	for _, m := range matches {
+		goalsA, goalsB := goalsFor(m, teamAID)
+		h2h.Played++
+		h2h.TeamAGoals += goalsA
+		h2h.TeamBGoals += goalsB
+
+		switch {
+		case goalsA > goalsB:
+			h2h.TeamAWins++
+		case goalsB > goalsA:
+			h2h.TeamBWins++
+		default:
+			h2h.Draws++
+		}
+	}
+
+	return h2h, nil
+}
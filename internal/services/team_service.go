@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+)
+
+// Team management roles, from most to least privileged
+const (
+	TeamRoleCaptain     = "captain"
+	TeamRoleViceCaptain = "vice_captain"
+	TeamRoleManager     = "manager"
+)
+
+var teamRoleRank = map[string]int{
+	TeamRoleCaptain:     3,
+	TeamRoleViceCaptain: 2,
+	TeamRoleManager:     1,
+}
+
+var (
+	ErrUnknownTeamRole   = errors.New("role must be vice_captain or manager")
+	ErrCaptainNotRemoved = errors.New("transfer the captaincy before removing the captain")
+	ErrUserIsCaptain     = errors.New("user captains a team; transfer the captaincy first")
+)
+
+// TeamRoleAtLeast reports whether role is as privileged as min
+func TeamRoleAtLeast(role, min string) bool {
+	return teamRoleRank[role] >= teamRoleRank[min] && teamRoleRank[role] > 0
+}
+
+type TeamService struct {
+	db *gorm.DB
+}
+
+func NewTeamService() *TeamService {
+	return &TeamService{
+		db: database.GetDB(),
+	}
+}
+
+// Members lists the users running a team, captain first
+func (s *TeamService) Members(teamID uint) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := s.db.Preload("User").Where("team_id = ?", teamID).
+		Order("FIELD(role, 'captain', 'vice_captain', 'manager'), id").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// MemberRoles returns the team roles a user holds, oldest first
+func (s *TeamService) MemberRoles(userID uint) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CreateTeam creates a team and, when a captain is set, their captain role
+func (s *TeamService) CreateTeam(team *models.Team) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		if team.CaptainID == 0 {
+			return nil
+		}
+		member := models.TeamMember{TeamID: team.ID, UserID: team.CaptainID, Role: TeamRoleCaptain}
+		return tx.Create(&member).Error
+	})
+}
+
+// UserTeam is a team the user belongs to, as listed by "my teams"
+type UserTeam struct {
+	TeamID    uint   `json:"team_id"`
+	TeamName  string `json:"team_name"`
+	LogoURL   string `json:"logo_url"`
+	Role      string `json:"role"`       // captain, vice_captain, manager or player
+	IsDefault bool   `json:"is_default"` // Used when a request names no team
+}
+
+// UserTeams lists every team the user runs or plays for
+func (s *TeamService) UserTeams(userID uint) ([]UserTeam, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	members, err := s.MemberRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[uint]string)
+	teamIDs := []uint{}
+	for _, m := range members {
+		roles[m.TeamID] = m.Role
+		teamIDs = append(teamIDs, m.TeamID)
+	}
+
+	// Player accounts also belong to the team they play for
+	if user.PlayerID != nil {
+		var player models.Player
+		if err := s.db.First(&player, *user.PlayerID).Error; err == nil && player.TeamID != nil {
+			if _, ok := roles[*player.TeamID]; !ok {
+				roles[*player.TeamID] = "player"
+				teamIDs = append(teamIDs, *player.TeamID)
+			}
+		}
+	}
+
+	teams := []UserTeam{}
+	if len(teamIDs) == 0 {
+		return teams, nil
+	}
+
+	var rows []models.Team
+	if err := s.db.Where("id IN ?", teamIDs).Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	defaultID, _ := s.DefaultTeam(&user, members)
+	for _, t := range rows {
+		teams = append(teams, UserTeam{
+			TeamID:    t.ID,
+			TeamName:  t.Name,
+			LogoURL:   t.LogoURL,
+			Role:      roles[t.ID],
+			IsDefault: t.ID == defaultID,
+		})
+	}
+	return teams, nil
+}
+
+// DefaultTeam picks the team used when a request names none: the user's own
+// team if they still run it, otherwise their only team. With several teams
+// and no usable default the caller has to pick one.
+func (s *TeamService) DefaultTeam(user *models.User, members []models.TeamMember) (uint, bool) {
+	if user.TeamID != nil {
+		for _, m := range members {
+			if m.TeamID == *user.TeamID {
+				return m.TeamID, true
+			}
+		}
+	}
+	if len(members) == 1 {
+		return members[0].TeamID, true
+	}
+	return 0, false
+}
+
+// SetMember gives a user the vice-captain or manager role on a team.
+// Captaincy only changes hands through TransferCaptaincy.
+func (s *TeamService) SetMember(teamID, userID uint, role string) (*models.TeamMember, error) {
+	if role != TeamRoleViceCaptain && role != TeamRoleManager {
+		return nil, ErrUnknownTeamRole
+	}
+
+	var member models.TeamMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Team{}, teamID).Error; err != nil {
+			return err
+		}
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.Role == "admin" {
+			return errors.New("admin accounts can't hold team roles")
+		}
+
+		err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			member = models.TeamMember{TeamID: teamID, UserID: userID, Role: role}
+			return tx.Create(&member).Error
+		}
+		if err != nil {
+			return err
+		}
+		if member.Role == TeamRoleCaptain {
+			return ErrCaptainNotRemoved
+		}
+		member.Role = role
+		return tx.Save(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveMember takes a user's role on a team away. The captain can't be removed.
+func (s *TeamService) RemoveMember(teamID, userID uint) error {
+	var member models.TeamMember
+	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		return err
+	}
+	if member.Role == TeamRoleCaptain {
+		return ErrCaptainNotRemoved
+	}
+	return s.db.Delete(&member).Error
+}
+
+// TransferCaptaincy hands the team to another user. The old captain stays on
+// as vice-captain so they keep access to the team.
+func (s *TeamService) TransferCaptaincy(teamID, newCaptainID uint) (*models.Team, error) {
+	var team models.Team
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&team, teamID).Error; err != nil {
+			return err
+		}
+		if team.CaptainID == newCaptainID {
+			return nil
+		}
+
+		var user models.User
+		if err := tx.First(&user, newCaptainID).Error; err != nil {
+			return err
+		}
+		if user.Role == "admin" {
+			return errors.New("admin accounts can't captain a team")
+		}
+
+		if team.CaptainID != 0 {
+			if err := tx.Model(&models.TeamMember{}).
+				Where("team_id = ? AND user_id = ?", teamID, team.CaptainID).
+				Update("role", TeamRoleViceCaptain).Error; err != nil {
+				return err
+			}
+		}
+
+		var member models.TeamMember
+		err := tx.Where("team_id = ? AND user_id = ?", teamID, newCaptainID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			member = models.TeamMember{TeamID: teamID, UserID: newCaptainID, Role: TeamRoleCaptain}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if err := tx.Model(&member).Update("role", TeamRoleCaptain).Error; err != nil {
+			return err
+		}
+
+		// Captain accounts without a team of their own now point at this one
+		if user.Role == "captain" && user.TeamID == nil {
+			if err := tx.Model(&user).Update("team_id", teamID).Error; err != nil {
+				return err
+			}
+		}
+
+		team.CaptainID = newCaptainID
+		return tx.Model(&team).Update("captain_id", newCaptainID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// RemoveUser drops all team roles of a user, e.g. before deleting the account.
+// Captains have to hand over their team first.
+func (s *TeamService) RemoveUser(userID uint) error {
+	var captaining int64
+	if err := s.db.Model(&models.Team{}).Where("captain_id = ?", userID).Count(&captaining).Error; err != nil {
+		return err
+	}
+	if captaining > 0 {
+		return ErrUserIsCaptain
+	}
+	return s.db.Where("user_id = ?", userID).Delete(&models.TeamMember{}).Error
+}
+
+// BackfillCaptains creates the captain role for teams that only have
+// Team.CaptainID, covering teams created before team roles existed.
+func (s *TeamService) BackfillCaptains() error {
+	var teams []models.Team
+	if err := s.db.Where("captain_id <> 0 AND id NOT IN (?)",
+		s.db.Model(&models.TeamMember{}).Select("team_id").Where("role = ?", TeamRoleCaptain),
+	).Find(&teams).Error; err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		if err := s.db.First(&models.User{}, team.CaptainID).Error; err != nil {
+			continue
+		}
+		var member models.TeamMember
+		if err := s.db.Where(models.TeamMember{TeamID: team.ID, UserID: team.CaptainID}).
+			Assign(models.TeamMember{Role: TeamRoleCaptain}).
+			FirstOrCreate(&member).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package services
 
 import (
+	"errors"
 	"math"
 
 	"github.com/yourname/leaguemaster/internal/models"
 	"github.com/yourname/leaguemaster/pkg/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TournamentService struct {
@@ -28,6 +30,49 @@ func (s *TournamentService) CreateTournament(name string, maxTeams int) (*models
 	return &tournament, err
 }
 
+var ErrTeamAlreadyRegistered = errors.New("team already in tournament")
+
+// RegisterTeam approves a team's entry into a tournament
+func (s *TournamentService) RegisterTeam(tournamentID, teamID uint) (*models.Standing, error) {
+	var tournament models.Tournament
+	if err := s.db.First(&tournament, tournamentID).Error; err != nil {
+		return nil, err
+	}
+	var team models.Team
+	if err := s.db.First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Standing{}).Where("tournament_id = ? AND team_id = ?", tournamentID, teamID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrTeamAlreadyRegistered
+	}
+
+	// A Standing entry registers the team
+	standing := models.Standing{TournamentID: tournamentID, TeamID: teamID}
+	event := DomainEvent{
+		Name:         EventTeamRegistrationApproved,
+		TournamentID: tournamentID,
+		TeamID:       teamID,
+		Data:         map[string]string{"team": team.Name, "tournament": tournament.Name},
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&standing).Error; err != nil {
+			return err
+		}
+		return enqueueWebhooks(tx, &event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Events().Publish(event)
+	return &standing, nil
+}
+
 // GenerateBracket creates a single-elimination bracket
 func (s *TournamentService) GenerateBracket(tournamentID uint) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
@@ -80,68 +125,76 @@ func (s *TournamentService) GenerateBracket(tournamentID uint) error {
 	})
 }
 
-// RecalculateStandings aggregates completed matches
+// RecalculateStandings aggregates completed matches. Registered teams that
+// haven't played keep their (empty) row, since it is their registration.
 func (s *TournamentService) RecalculateStandings(tournamentID uint) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		// Clear existing standings
-		if err := tx.Where("tournament_id = ?", tournamentID).Delete(&models.Standing{}).Error; err != nil {
-			return err
-		}
+		return recalculateStandings(tx, tournamentID)
+	})
+}
 
-		// Get all completed matches
-		var matches []models.Match
-		if err := tx.Where("tournament_id = ? AND status = ?", tournamentID, "completed").Find(&matches).Error; err != nil {
-			return err
-		}
+// recalculateStandings is RecalculateStandings within the caller's transaction
+func recalculateStandings(tx *gorm.DB, tournamentID uint) error {
+	// Reset existing standings
+	if err := tx.Model(&models.Standing{}).Where("tournament_id = ?", tournamentID).Updates(map[string]interface{}{
+		"points": 0, "wins": 0, "losses": 0, "draws": 0, "goals_for": 0, "goals_against": 0,
+	}).Error; err != nil {
+		return err
+	}
 
-		stats := make(map[uint]*models.Standing)
-
-		for _, m := range matches {
-			if m.TeamAID != nil {
-				if _, ok := stats[*m.TeamAID]; !ok {
-					stats[*m.TeamAID] = &models.Standing{TournamentID: tournamentID, TeamID: *m.TeamAID}
-				}
-				teamA := stats[*m.TeamAID]
-				teamA.GoalsFor += m.ScoreA
-				teamA.GoalsAgainst += m.ScoreB
-
-				if m.ScoreA > m.ScoreB {
-					teamA.Wins++
-					teamA.Points += 3
-				} else if m.ScoreA == m.ScoreB {
-					teamA.Draws++
-					teamA.Points += 1
-				} else {
-					teamA.Losses++
-				}
-			}
+	// Get all completed matches
+	var matches []models.Match
+	if err := tx.Where("tournament_id = ? AND status = ?", tournamentID, "completed").Find(&matches).Error; err != nil {
+		return err
+	}
+
+	stats := make(map[uint]*models.Standing)
 
-			if m.TeamBID != nil {
-				if _, ok := stats[*m.TeamBID]; !ok {
-					stats[*m.TeamBID] = &models.Standing{TournamentID: tournamentID, TeamID: *m.TeamBID}
-				}
-				teamB := stats[*m.TeamBID]
-				teamB.GoalsFor += m.ScoreB
-				teamB.GoalsAgainst += m.ScoreA
-
-				if m.ScoreB > m.ScoreA {
-					teamB.Wins++
-					teamB.Points += 3
-				} else if m.ScoreB == m.ScoreA {
-					teamB.Draws++
-					teamB.Points += 1
-				} else {
-					teamB.Losses++
-				}
+	for _, m := range matches {
+		if m.TeamAID != nil {
+			if _, ok := stats[*m.TeamAID]; !ok {
+				stats[*m.TeamAID] = &models.Standing{TournamentID: tournamentID, TeamID: *m.TeamAID}
+			}
+			teamA := stats[*m.TeamAID]
+			teamA.GoalsFor += m.ScoreA
+			teamA.GoalsAgainst += m.ScoreB
+
+			if m.ScoreA > m.ScoreB {
+				teamA.Wins++
+				teamA.Points += 3
+			} else if m.ScoreA == m.ScoreB {
+				teamA.Draws++
+				teamA.Points += 1
+			} else {
+				teamA.Losses++
 			}
 		}
 
-		for _, stat := range stats {
-			if err := tx.Create(stat).Error; err != nil {
-				return err
+		if m.TeamBID != nil {
+			if _, ok := stats[*m.TeamBID]; !ok {
+				stats[*m.TeamBID] = &models.Standing{TournamentID: tournamentID, TeamID: *m.TeamBID}
+			}
+			teamB := stats[*m.TeamBID]
+			teamB.GoalsFor += m.ScoreB
+			teamB.GoalsAgainst += m.ScoreA
+
+			if m.ScoreB > m.ScoreA {
+				teamB.Wins++
+				teamB.Points += 3
+			} else if m.ScoreB == m.ScoreA {
+				teamB.Draws++
+				teamB.Points += 1
+			} else {
+				teamB.Losses++
 			}
 		}
+	}
 
-		return nil
-	})
+	for _, stat := range stats {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(stat).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
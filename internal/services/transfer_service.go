@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferWindowClosed = errors.New("transfer window is closed")
+	ErrTransferNotPending   = errors.New("transfer is no longer pending")
+	ErrTransferForbidden    = errors.New("transfer does not involve your team")
+	ErrAlreadyInTeam        = errors.New("player is already in your team")
+	ErrTransferPending      = errors.New("player already has a pending transfer")
+	ErrPlayerMoved          = errors.New("player has changed teams since the request")
+)
+
+type TransferService struct {
+	db *gorm.DB
+}
+
+func NewTransferService() *TransferService {
+	return &TransferService{
+		db: database.GetDB(),
+	}
+}
+
+// OpenWindow returns the transfer window open at the given time, if any
+func (s *TransferService) OpenWindow(at time.Time) (*models.TransferWindow, error) {
+	return openWindow(s.db, at)
+}
+
+func openWindow(tx *gorm.DB, at time.Time) (*models.TransferWindow, error) {
+	var window models.TransferWindow
+	err := tx.Where("opens_at <= ? AND closes_at >= ?", at, at).Order("closes_at desc").First(&window).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// RequestTransfer is made by the captain of the receiving team
+func (s *TransferService) RequestTransfer(userID, toTeamID, playerID uint, note string) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.First(&player, playerID).Error; err != nil {
+			return err
+		}
+		if onTeam(player.TeamID, &toTeamID) {
+			return ErrAlreadyInTeam
+		}
+
+		var pending int64
+		if err := tx.Model(&models.Transfer{}).Where("player_id = ? AND status IN ?", playerID, []string{"requested", "released"}).Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrTransferPending
+		}
+
+		transfer = models.Transfer{
+			PlayerID:      playerID,
+			FromTeamID:    player.TeamID,
+			ToTeamID:      toTeamID,
+			Status:        "requested",
+			Note:          note,
+			RequestedByID: userID,
+		}
+		// Free agents have nobody to release them
+		if player.TeamID == nil {
+			transfer.Status = "released"
+		}
+		return tx.Create(&transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// lockedTransfer loads and locks a transfer, so its status can't change
+// (an approval moving the player, say) until the caller's transaction ends
+func lockedTransfer(tx *gorm.DB, transferID uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, transferID).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ReleaseTransfer is the releasing captain agreeing to let the player go
+func (s *TransferService) ReleaseTransfer(transferID, teamID, userID uint) (*models.Transfer, error) {
+	var transfer *models.Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = lockedTransfer(tx, transferID); err != nil {
+			return err
+		}
+		if !onTeam(transfer.FromTeamID, &teamID) {
+			return ErrTransferForbidden
+		}
+		if transfer.Status != "requested" {
+			return ErrTransferNotPending
+		}
+
+		now := time.Now()
+		transfer.Status = "released"
+		transfer.ReleasedByID = &userID
+		transfer.ReleasedAt = &now
+		return tx.Save(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// DeclineTransfer lets either captain back out before an admin has decided.
+// The releasing side rejects, the requesting side cancels.
+func (s *TransferService) DeclineTransfer(transferID, teamID uint) (*models.Transfer, error) {
+	var transfer *models.Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = lockedTransfer(tx, transferID); err != nil {
+			return err
+		}
+		if transfer.Status != "requested" && transfer.Status != "released" {
+			return ErrTransferNotPending
+		}
+
+		switch {
+		case onTeam(transfer.FromTeamID, &teamID):
+			transfer.Status = "rejected"
+		case transfer.ToTeamID == teamID:
+			transfer.Status = "cancelled"
+		default:
+			return ErrTransferForbidden
+		}
+		return tx.Save(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// inActiveTournament reports whether any of the teams is registered in a
+// tournament that is being played
+func inActiveTournament(tx *gorm.DB, teamIDs ...uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.Standing{}).
+		Joins("JOIN tournaments ON tournaments.id = standings.tournament_id").
+		Where("standings.team_id IN ? AND tournaments.status = ?", teamIDs, "active").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ApproveTransfer moves the player once both captains agreed. Teams playing
+// an active tournament can only move players inside an open window.
+func (s *TransferService) ApproveTransfer(transferID, adminID uint) (*models.Transfer, error) {
+	var transfer *models.Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = lockedTransfer(tx, transferID); err != nil {
+			return err
+		}
+		if transfer.Status != "released" {
+			return ErrTransferNotPending
+		}
+
+		window, err := openWindow(tx, time.Now())
+		if err != nil {
+			return err
+		}
+		if window == nil {
+			teams := []uint{transfer.ToTeamID}
+			if transfer.FromTeamID != nil {
+				teams = append(teams, *transfer.FromTeamID)
+			}
+			playing, err := inActiveTournament(tx, teams...)
+			if err != nil {
+				return err
+			}
+			if playing {
+				return ErrTransferWindowClosed
+			}
+		}
+
+		var player models.Player
+		if err := tx.First(&player, transfer.PlayerID).Error; err != nil {
+			return err
+		}
+		if !sameTeam(player.TeamID, transfer.FromTeamID) {
+			return ErrPlayerMoved
+		}
+
+		if err := assignTeam(tx, &player, &transfer.ToTeamID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transfer.Status = "approved"
+		transfer.ReviewedByID = &adminID
+		transfer.ReviewedAt = &now
+		return tx.Save(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// RejectTransfer is an admin turning down a pending transfer
+func (s *TransferService) RejectTransfer(transferID, adminID uint, note string) (*models.Transfer, error) {
+	var transfer *models.Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = lockedTransfer(tx, transferID); err != nil {
+			return err
+		}
+		if transfer.Status != "requested" && transfer.Status != "released" {
+			return ErrTransferNotPending
+		}
+
+		now := time.Now()
+		transfer.Status = "rejected"
+		transfer.ReviewedByID = &adminID
+		transfer.ReviewedAt = &now
+		if note != "" {
+			transfer.Note = note
+		}
+		return tx.Save(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
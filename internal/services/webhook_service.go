@@ -0,0 +1,495 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yourname/leaguemaster/internal/models"
+	"github.com/yourname/leaguemaster/pkg/database"
+	"github.com/yourname/leaguemaster/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// EventWebhookTest is sent by the "send test event" action only
+const EventWebhookTest = "webhook.test"
+
+// WebhookEvents are the domain events integrations can subscribe to
+var WebhookEvents = []string{
+	EventMatchScheduled,
+	EventMatchCompleted,
+	EventResultSubmitted,
+	EventResultDisputed,
+	EventStandingsChanged,
+	EventTeamRegistrationApproved,
+	EventPlayerSuspended,
+}
+
+// Headers of a webhook delivery. The signature is
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" keyed with the secret>";
+// receivers should recompute it and reject old timestamps.
+const (
+	WebhookEventHeader     = "X-LeagueMaster-Event"
+	WebhookDeliveryHeader  = "X-LeagueMaster-Delivery"
+	WebhookSignatureHeader = "X-LeagueMaster-Signature"
+)
+
+var (
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	errBlockedAddress = errors.New("webhook address is not public")
+)
+
+// webhookBatch caps how many deliveries are sent per dispatcher pass
+const webhookBatch = 100
+
+// webhookWake lets new deliveries skip the wait for the next dispatcher tick
+var webhookWake = make(chan struct{}, 1)
+
+// WebhookPolicy sets the timeouts, retry backoff and log retention
+type WebhookPolicy struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration // Wait after the first failure, doubled each time
+	BackoffMax   time.Duration
+	Lease        time.Duration // How long a claimed delivery is left to its sender before another instance retries it
+	LogRetention time.Duration // Finished deliveries are kept this long
+}
+
+func webhookPolicyFromEnv() WebhookPolicy {
+	return WebhookPolicy{
+		Timeout:      utils.EnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:  utils.EnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		BackoffBase:  utils.EnvDuration("WEBHOOK_BACKOFF_BASE", 30*time.Second),
+		BackoffMax:   utils.EnvDuration("WEBHOOK_BACKOFF_MAX", 2*time.Hour),
+		Lease:        utils.EnvDuration("WEBHOOK_LEASE", time.Minute),
+		LogRetention: utils.EnvDuration("WEBHOOK_LOG_RETENTION", 30*24*time.Hour),
+	}
+}
+
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+	policy WebhookPolicy
+}
+
+func NewWebhookService() *WebhookService {
+	policy := webhookPolicyFromEnv()
+	return &WebhookService{
+		db:     database.GetDB(),
+		client: webhookClient(policy.Timeout),
+		policy: policy,
+	}
+}
+
+// publicIP reports whether webhooks may be sent to the address: anything but
+// the server's own host and the networks around it
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// webhookClient posts to public addresses only. The address is checked when
+// dialing, after the host is resolved, so a name pointing (or rebound) to an
+// internal address is refused as well. Redirects aren't followed, since they
+// could lead anywhere; a 3xx answer is a failed delivery.
+func webhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// No proxy: the dialer has to see the endpoint's own address
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// WebhookRequest creates or updates a subscription
+type WebhookRequest struct {
+	Name         string   `json:"name"`
+	URL          string   `json:"url"`
+	Events       []string `json:"events"` // WebhookEvents, or ["*"] for all of them
+	TournamentID *uint    `json:"tournament_id"`
+	IsActive     *bool    `json:"is_active"` // Updates only
+}
+
+func (s *WebhookService) validate(req *WebhookRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWebhook)
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an http(s) URL", ErrInvalidWebhook)
+	}
+	// Names are checked again on every delivery, once resolved
+	if ip := net.ParseIP(u.Hostname()); strings.EqualFold(u.Hostname(), "localhost") || (ip != nil && !publicIP(ip)) {
+		return fmt.Errorf("%w: url must point to a public address", ErrInvalidWebhook)
+	}
+	if len(req.Events) == 0 {
+		return fmt.Errorf("%w: subscribe to at least one event", ErrInvalidWebhook)
+	}
+	for _, event := range req.Events {
+		if event != AllEvents && !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("%w: unknown event '%s'", ErrInvalidWebhook, event)
+		}
+	}
+	if req.TournamentID != nil {
+		if err := s.db.First(&models.Tournament{}, *req.TournamentID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create adds a subscription and returns it with its signing secret, which
+// isn't shown again
+func (s *WebhookService) Create(req WebhookRequest, adminID uint) (*models.WebhookSubscription, string, error) {
+	if err := s.validate(&req); err != nil {
+		return nil, "", err
+	}
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sub := models.WebhookSubscription{
+		Name:         req.Name,
+		URL:          req.URL,
+		Secret:       secret,
+		Events:       req.Events,
+		TournamentID: req.TournamentID,
+		IsActive:     true,
+		CreatedByID:  adminID,
+	}
+	if err := s.db.Create(&sub).Error; err != nil {
+		return nil, "", err
+	}
+	return &sub, secret, nil
+}
+
+func (s *WebhookService) List() ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	if err := s.db.Order("id").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (s *WebhookService) Get(id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := s.db.First(&sub, id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update replaces a subscription's settings
+func (s *WebhookService) Update(id uint, req WebhookRequest) (*models.WebhookSubscription, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(&req); err != nil {
+		return nil, err
+	}
+
+	sub.Name = req.Name
+	sub.URL = req.URL
+	sub.Events = req.Events
+	sub.TournamentID = req.TournamentID
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+	if err := s.db.Select("name", "url", "events", "tournament_id", "is_active").Updates(sub).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription and its delivery log
+func (s *WebhookService) Delete(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.WebhookSubscription{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+// RotateSecret replaces a subscription's signing secret and returns the new one
+func (s *WebhookService) RotateSecret(id uint) (string, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return "", err
+	}
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.db.Model(sub).Update("secret", secret).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Deliveries returns a subscription's delivery log, newest first
+func (s *WebhookService) Deliveries(id uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	var deliveries []models.WebhookDelivery
+	if err := s.db.Where("subscription_id = ?", id).Order("id desc").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// webhookMatches reports whether a subscription wants the event
+func webhookMatches(sub models.WebhookSubscription, event DomainEvent) bool {
+	if !slices.Contains(sub.Events, event.Name) && !slices.Contains(sub.Events, AllEvents) {
+		return false
+	}
+	// Events not tied to a tournament (a player's suspension) only reach
+	// subscriptions without a tournament filter
+	return sub.TournamentID == nil || *sub.TournamentID == event.TournamentID
+}
+
+// enqueueWebhooks queues a delivery of the event to every subscription that
+// wants it. Call it in the transaction of the change the event is about, so
+// the deliveries are saved if and only if the change is.
+func enqueueWebhooks(tx *gorm.DB, event *DomainEvent) error {
+	event.stamp()
+	if !slices.Contains(WebhookEvents, event.Name) {
+		return nil
+	}
+
+	var subs []models.WebhookSubscription
+	if err := tx.Where("is_active = ?", true).Find(&subs).Error; err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, sub := range subs {
+		if webhookMatches(sub, *event) {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				SubscriptionID: sub.ID,
+				Event:          event.Name,
+				Payload:        string(payload),
+				Status:         "pending",
+				NextAttemptAt:  time.Now(),
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// wakeWebhookDispatcher has the dispatcher look for due deliveries right away
+func wakeWebhookDispatcher() {
+	wakeDispatcher(webhookWake)
+}
+
+// SendTest posts a test event to the subscription right away, whatever its
+// filters, and returns the logged delivery. A failed test is not retried.
+func (s *WebhookService) SendTest(id uint) (*models.WebhookDelivery, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(DomainEvent{
+		Name:       EventWebhookTest,
+		Data:       map[string]string{"message": "Test event from LeagueMaster"},
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		Event:          EventWebhookTest,
+		Payload:        string(payload),
+		Status:         "pending",
+		Attempts:       1,
+		NextAttemptAt:  time.Now().Add(s.policy.Lease),
+	}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	s.deliver(sub, &delivery, 1)
+	return &delivery, nil
+}
+
+// Redeliver queues a delivery again, e.g. once a failing endpoint is fixed
+func (s *WebhookService) Redeliver(subscriptionID, deliveryID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := s.db.Where("id = ? AND subscription_id = ?", deliveryID, subscriptionID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.db.Model(&delivery).Select("status", "attempts", "next_attempt_at").Updates(&delivery).Error; err != nil {
+		return nil, err
+	}
+	wakeWebhookDispatcher()
+	return &delivery, nil
+}
+
+// SignWebhook computes the signature header of a body
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// queue is the dispatcher of the webhook deliveries
+func (s *WebhookService) queue() *dispatcher[models.WebhookDelivery] {
+	return &dispatcher[models.WebhookDelivery]{
+		name:  "Webhook",
+		db:    s.db,
+		batch: webhookBatch,
+		lease: s.policy.Lease,
+		wake:  webhookWake,
+		claimKey: func(d *models.WebhookDelivery) (uint, *int) {
+			return d.ID, &d.Attempts
+		},
+		send: func(d *models.WebhookDelivery) bool {
+			sub, err := s.Get(d.SubscriptionID)
+			if err != nil || !sub.IsActive {
+				s.db.Model(d).Updates(map[string]interface{}{"status": "failed", "last_error": "subscription was removed or disabled"})
+				return false
+			}
+			s.deliver(sub, d, s.policy.MaxAttempts)
+			return true
+		},
+		prune: s.Prune,
+	}
+}
+
+// DispatchDue sends the deliveries whose time has come and returns how many it tried
+func (s *WebhookService) DispatchDue(now time.Time) (int, error) {
+	return s.queue().dispatchDue(now)
+}
+
+// deliver posts a claimed delivery and records the outcome, scheduling a
+// retry unless maxAttempts is reached
+func (s *WebhookService) deliver(sub *models.WebhookSubscription, d *models.WebhookDelivery, maxAttempts int) {
+	body := []byte(d.Payload)
+	status, err := s.post(sub, d, body)
+
+	updates := map[string]interface{}{
+		"response_status": status,
+		"last_error":      "",
+	}
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = "delivered"
+		updates["delivered_at"] = now
+		d.Status, d.DeliveredAt = "delivered", &now
+	case d.Attempts >= maxAttempts:
+		log.Printf("Webhook delivery %d to %s gave up after %d attempts: %v", d.ID, sub.URL, d.Attempts, err)
+		updates["status"] = "failed"
+		updates["last_error"] = err.Error()
+		d.Status = "failed"
+	default:
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = time.Now().Add(retryBackoff(s.policy.BackoffBase, s.policy.BackoffMax, d.Attempts))
+	}
+	if err != nil {
+		d.LastError = err.Error()
+	}
+	d.ResponseStatus = status
+	s.db.Model(d).Updates(updates)
+}
+
+// post sends the body to the subscription's URL and returns the answer's
+// status. Anything but a 2xx answer is an error. The answer's body isn't kept,
+// so an endpoint can't have the log store whatever it likes.
+func (s *WebhookService) post(sub *models.WebhookSubscription, d *models.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LeagueMaster-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, d.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(sub.Secret, time.Now().Unix(), body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("endpoint answered %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// Prune drops finished deliveries past the log retention
+func (s *WebhookService) Prune(now time.Time) error {
+	return s.db.Where("status IN ? AND updated_at < ?", []string{"delivered", "failed"}, now.Add(-s.policy.LogRetention)).
+		Delete(&models.WebhookDelivery{}).Error
+}
+
+// RunDispatcher sends due deliveries every interval, or right away when some
+// are queued, and prunes the log hourly. Meant to run in its own goroutine.
+func (s *WebhookService) RunDispatcher(interval time.Duration) {
+	s.queue().run(interval)
+}
+
+// RegisterWebhookSubscribers wakes the dispatcher when an event is published.
+// The deliveries themselves are queued with the change (see enqueueWebhooks),
+// so none is lost if the process stops before the dispatcher runs.
+func RegisterWebhookSubscribers(bus *EventBus) {
+	bus.Subscribe(AllEvents, func(event DomainEvent) {
+		if slices.Contains(WebhookEvents, event.Name) {
+			wakeWebhookDispatcher()
+		}
+	})
+}
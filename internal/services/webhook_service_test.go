@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Cloud metadata
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	// The server listens on loopback, like any service next to the app would
+	_, err := webhookClient(time.Second).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, errBlockedAddress) {
+		t.Errorf("got %v, want errBlockedAddress", err)
+	}
+	if hit {
+		t.Error("the request reached the server")
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	client := webhookClient(time.Second)
+	if err := client.CheckRedirect(nil, nil); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Errorf("got %v, want http.ErrUseLastResponse", err)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://hooks.example.com/leaguemaster"},
+		{url: "http://93.184.216.34:8080/hook"},
+		{url: "ftp://hooks.example.com", wantErr: true},
+		{url: "http://localhost:8080/hook", wantErr: true},
+		{url: "http://127.0.0.1/hook", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://10.0.0.5/hook", wantErr: true},
+	}
+	s := &WebhookService{}
+	for _, tt := range tests {
+		req := WebhookRequest{Name: "CI", URL: tt.url, Events: []string{EventMatchCompleted}}
+		err := s.validate(&req)
+		if tt.wantErr && !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("%s: got %v, want ErrInvalidWebhook", tt.url, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: got %v, want no error", tt.url, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string // Optional
+}
+
+// Sender delivers emails. Pick one with MAIL_DRIVER.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// ErrNotConfigured is returned by the sender used when MAIL_DRIVER is not set
+var ErrNotConfigured = errors.New("mail is not configured, set MAIL_DRIVER")
+
+// ErrInvalidAddress is returned for a recipient that isn't one plain address
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// CheckAddress accepts a single plain address such as name@example.com.
+// Display names, lists and line breaks, which would end up in the To header
+// as they are, are refused.
+func CheckAddress(addr string) error {
+	if strings.ContainsAny(addr, "\r\n") {
+		return ErrInvalidAddress
+	}
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Address != addr {
+		return ErrInvalidAddress
+	}
+	return nil
+}
+
+// development reports whether APP_ENV allows the log and file drivers
+func development() bool {
+	return strings.EqualFold(os.Getenv("APP_ENV"), "development")
+}
+
+// CheckConfig reports a missing or unsafe MAIL_DRIVER, meant to stop startup.
+// The log and file drivers keep the emails (and the links in them) around, so
+// they are only allowed with APP_ENV=development.
+func CheckConfig() error {
+	switch driver := strings.ToLower(os.Getenv("MAIL_DRIVER")); driver {
+	case "smtp":
+		if os.Getenv("SMTP_HOST") == "" {
+			return errors.New("MAIL_DRIVER=smtp needs SMTP_HOST")
+		}
+		return nil
+	case "log", "file":
+		if !development() {
+			return fmt.Errorf("MAIL_DRIVER=%s is only allowed with APP_ENV=development", driver)
+		}
+		return nil
+	case "":
+		return ErrNotConfigured
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+// NewFromEnv builds the sender configured by MAIL_DRIVER. Without one, sending fails.
+func NewFromEnv() Sender {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "LeagueMaster <no-reply@leaguemaster.local>"
+	}
+
+	switch strings.ToLower(os.Getenv("MAIL_DRIVER")) {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPSender{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return &FileSender{Dir: dir, From: from}
+	case "log":
+		return &LogSender{From: from}
+	default:
+		return disabledSender{}
+	}
+}
+
+type disabledSender struct{}
+
+func (disabledSender) Send(msg Message) error {
+	return ErrNotConfigured
+}
+
+// LogSender writes emails to the application log, for local development.
+// Tokens in links are redacted; use the file driver to follow them.
+type LogSender struct {
+	From string
+}
+
+var tokenParam = regexp.MustCompile(`(?i)(token=)[^\s&"]+`)
+
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, tokenParam.ReplaceAllString(msg.Text, "${1}[redacted]"))
+	return nil
+}
+
+// FileSender writes each email as an .eml file that any mail client can open
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+func (s *FileSender) Send(msg Message) error {
+	if err := CheckAddress(msg.To); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(s.Dir, name), Render(s.From, msg), 0o644)
+}
+
+// SMTPSender delivers through any SMTP server. STARTTLS is used when the
+// server offers it; without a username no authentication is attempted, which
+// suits local test servers such as MailHog (SMTP_HOST=localhost, SMTP_PORT=1025).
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+	if err := CheckAddress(msg.To); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, from.Address, []string{msg.To}, Render(s.From, msg))
+}
+
+// Render builds the raw RFC 5322 message, multipart when there is an HTML
+// body. The recipient is written as is; senders check it with CheckAddress.
+func Render(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(msg.Text)
+		return []byte(b.String())
+	}
+
+	boundary := fmt.Sprintf("leaguemaster-%d", time.Now().UnixNano())
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", boundary, msg.Text)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n", boundary, msg.HTML)
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
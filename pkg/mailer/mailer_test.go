@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: "captain@example.com"},
+		{addr: "first.last+league@sub.example.org"},
+		{addr: "", wantErr: true},
+		{addr: "captain", wantErr: true},
+		{addr: "Captain <captain@example.com>", wantErr: true},
+		{addr: "captain@example.com, other@example.com", wantErr: true},
+		{addr: "captain@example.com\r\nBcc: victim@example.com", wantErr: true},
+		{addr: "captain@example.com\nSubject: hi", wantErr: true},
+		{addr: " captain@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := CheckAddress(tt.addr)
+		if tt.wantErr && !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("%q: got %v, want ErrInvalidAddress", tt.addr, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%q: got %v, want no error", tt.addr, err)
+		}
+	}
+}
+
+func TestFileSenderRefusesHeaderInjection(t *testing.T) {
+	s := &FileSender{Dir: t.TempDir(), From: "no-reply@example.com"}
+	err := s.Send(Message{To: "captain@example.com\r\nBcc: victim@example.com", Subject: "Hi", Text: "Hi"})
+	if !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("got %v, want ErrInvalidAddress", err)
+	}
+}
@@ -0,0 +1,379 @@
+// Package oidc is a small OpenID Connect client for the authorization code
+// flow with PKCE. Providers are discovered from their issuer URL, so any
+// compliant server works, including a local mock for development.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// client_secret_basic (default) or client_secret_post
+	AuthMethod string
+
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims are the ID token claims used to find or create an account
+type Claims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// ProvidersFromEnv reads the providers listed in OIDC_PROVIDERS (comma
+// separated names). Each name is configured with OIDC_<NAME>_ISSUER,
+// _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and optionally _SCOPES and
+// _AUTH_METHOD. Providers missing an issuer or client ID are skipped.
+func ProvidersFromEnv() map[string]*Provider {
+	providers := make(map[string]*Provider)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		p := &Provider{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			AuthMethod:   os.Getenv(prefix + "AUTH_METHOD"),
+			client:       &http.Client{Timeout: 10 * time.Second},
+		}
+		if p.Issuer == "" || p.ClientID == "" {
+			continue
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers[name] = p
+	}
+	return providers
+}
+
+// NewVerifier returns a random PKCE code verifier
+func NewVerifier() (string, error) {
+	return randomString(32)
+}
+
+// Challenge is the S256 PKCE challenge for a verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL is where the user is sent to sign in
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens and returns the verified ID token claims
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {p.ClientID},
+	}
+	if p.AuthMethod == "client_secret_post" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.AuthMethod != "client_secret_post" && p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("token endpoint: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("token endpoint: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("token endpoint returned no id_token")
+	}
+
+	return p.Verify(ctx, body.IDToken, nonce)
+}
+
+// Verify checks the ID token signature against the provider's keys, and its
+// issuer, audience, expiry and nonce.
+func (p *Provider) Verify(ctx context.Context, idToken, nonce string) (*Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id_token: missing subject")
+	}
+	return claims, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	// Fetched without the lock, so a slow provider doesn't hold up key
+	// lookups; concurrent first calls may both fetch, which is harmless
+	var d discovery
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("discovery for %s: %w", p.Name, err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("discovery for %s: issuer mismatch %q", p.Name, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery for %s: missing endpoints", p.Name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery == nil {
+		p.discovery = &d
+	}
+	return p.discovery, nil
+}
+
+// key returns the signing key with the given ID, refetching the key set when
+// the ID is unknown (keys rotate) but at most once a minute.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	k, ok := p.lookupKey(kid)
+	recent := time.Since(p.keysAt) < time.Minute
+	if !ok && !recent {
+		// Claim the refetch, so concurrent lookups don't all fetch
+		p.keysAt = time.Now()
+	}
+	p.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+	if recent {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks for %s: %w", p.Name, err)
+	}
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a key by ID; tokens without a kid match a single-key set
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if k, ok := p.keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jwk is a JSON Web Key; only RSA and EC public keys are supported
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
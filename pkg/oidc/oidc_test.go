@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider serves discovery, the key set and the token endpoint, and
+// issues an ID token for the code it handed out
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	challenge string // From the authorization request
+	nonce     string // Put in the ID token
+	expiresIn time.Duration
+	audience  string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{t: t, key: key, expiresIn: time.Hour, audience: "client"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" || Challenge(r.Form.Get("code_verifier")) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.idToken()})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockProvider) idToken() string {
+	now := time.Now()
+	claims := Claims{
+		Email:         "captain@example.com",
+		EmailVerified: true,
+		Nonce:         m.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.server.URL,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{m.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiresIn)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	return signed
+}
+
+func (m *mockProvider) provider() *Provider {
+	return &Provider{
+		Name:        "mock",
+		Issuer:      m.server.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost/auth/oidc/mock/callback",
+		Scopes:      []string{"openid", "email"},
+		client:      m.server.Client(),
+	}
+}
+
+// authorize starts a sign-in like the service does and records the PKCE
+// challenge the provider would have been sent
+func (m *mockProvider) authorize(p *Provider, nonce string) string {
+	verifier, err := NewVerifier()
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", nonce, verifier)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, m.server.URL+"/authorize?") {
+		m.t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if q.Get("state") != "state-1" || q.Get("nonce") != nonce || q.Get("code_challenge_method") != "S256" {
+		m.t.Fatalf("unexpected authorization parameters %v", q)
+	}
+	m.challenge = q.Get("code_challenge")
+	return verifier
+}
+
+func TestExchange(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	m.nonce = "nonce-1"
+	verifier := m.authorize(p, "nonce-1")
+
+	claims, err := p.Exchange(context.Background(), "good-code", verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "captain@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *mockProvider)
+		code  string
+		nonce string
+	}{
+		{name: "nonce mismatch", setup: func(m *mockProvider) { m.nonce = "someone-elses-nonce" }},
+		{name: "expired id_token", setup: func(m *mockProvider) { m.expiresIn = -time.Hour }},
+		{name: "wrong audience", setup: func(m *mockProvider) { m.audience = "another-client" }},
+		{name: "bad code", code: "stolen-code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockProvider(t)
+			p := m.provider()
+			m.nonce = "nonce-1"
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			verifier := m.authorize(p, "nonce-1")
+
+			code := tt.code
+			if code == "" {
+				code = "good-code"
+			}
+			if _, err := p.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+				t.Fatal("Exchange succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	m.nonce = "nonce-1"
+	m.authorize(p, "nonce-1")
+
+	other, _ := NewVerifier()
+	if _, err := p.Exchange(context.Background(), "good-code", other, "nonce-1"); err == nil {
+		t.Fatal("Exchange succeeded with another PKCE verifier")
+	}
+}
@@ -0,0 +1,112 @@
+package pubsub
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Broker fans messages out to the subscribers of a topic. Publishing never
+// blocks: a subscriber whose buffer is full misses the message (and its
+// Dropped count goes up), so one slow client can't hold up the others.
+type Broker struct {
+	mu          sync.Mutex
+	buffer      int
+	maxPerTopic int
+	topics      map[string][]*Subscription
+}
+
+// NewBroker creates a broker with the given per-subscriber buffer. When a
+// topic has more than maxPerTopic subscribers (0 for no limit) the oldest one
+// is closed, which cleans up after clients that went away without notice.
+func NewBroker(buffer, maxPerTopic int) *Broker {
+	return &Broker{
+		buffer:      buffer,
+		maxPerTopic: maxPerTopic,
+		topics:      make(map[string][]*Subscription),
+	}
+}
+
+// Subscription receives a topic's messages on C until it is closed, by Close
+// or by the broker evicting it. C is closed then.
+type Subscription struct {
+	C <-chan interface{}
+
+	ch      chan interface{}
+	broker  *Broker
+	topic   string
+	closed  bool // Guarded by broker.mu
+	dropped int64
+}
+
+// Subscribe starts receiving a topic. Always Close the subscription when done.
+func (b *Broker) Subscribe(topic string) *Subscription {
+	ch := make(chan interface{}, b.buffer)
+	sub := &Subscription{C: ch, ch: ch, broker: b, topic: topic}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := append(b.topics[topic], sub)
+	if b.maxPerTopic > 0 && len(subs) > b.maxPerTopic {
+		oldest := subs[0]
+		oldest.closed = true
+		close(oldest.ch)
+		subs = subs[1:]
+	}
+	b.topics[topic] = subs
+	return sub
+}
+
+// Publish sends a message to every subscriber of the topic and returns how
+// many got it
+func (b *Broker) Publish(topic string, msg interface{}) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delivered := 0
+	for _, sub := range b.topics[topic] {
+		select {
+		case sub.ch <- msg:
+			delivered++
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+		}
+	}
+	return delivered
+}
+
+// Subscribers counts the open subscriptions of a topic
+func (b *Broker) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.topics[topic])
+}
+
+// Close stops the subscription and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+
+	subs := b.topics[s.topic]
+	for i, sub := range subs {
+		if sub == s {
+			subs = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(subs) == 0 {
+		delete(b.topics, s.topic)
+	} else {
+		b.topics[s.topic] = subs
+	}
+}
+
+// Dropped counts the messages this subscriber missed because it fell behind
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
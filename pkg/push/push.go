@@ -0,0 +1,98 @@
+package push
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidToken means the device token is dead (app uninstalled, token
+// rotated) and should be forgotten. Any other error is worth a retry.
+var ErrInvalidToken = errors.New("invalid device token")
+
+type Message struct {
+	Token    string
+	Platform string // ios, android or web
+	Title    string
+	Body     string
+	Data     map[string]string // Delivered to the app alongside the alert
+}
+
+// Provider delivers push messages. Pick one with PUSH_DRIVER.
+type Provider interface {
+	Send(msg Message) error
+}
+
+// NewFromEnv builds the provider configured by PUSH_DRIVER, or returns nil
+// when push is off (no driver set)
+func NewFromEnv() Provider {
+	switch driver := strings.ToLower(os.Getenv("PUSH_DRIVER")); driver {
+	case "log":
+		return &LogProvider{}
+	case "":
+		return nil
+	default:
+		log.Printf("Unknown PUSH_DRIVER %q, push notifications are off", driver)
+		return nil
+	}
+}
+
+// LogProvider writes push messages to the application log, for local
+// development. Tokens are credentials, so only the platform is logged.
+type LogProvider struct{}
+
+func (p *LogProvider) Send(msg Message) error {
+	log.Printf("Push to %s device: %s", msg.Platform, msg.Title)
+	return nil
+}
+
+// FakeProvider records messages instead of sending them, for tests. Tokens
+// can be marked dead, and sends made to fail, to exercise the retry paths.
+type FakeProvider struct {
+	mu       sync.Mutex
+	sent     []Message
+	invalid  map[string]bool
+	failures int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{invalid: make(map[string]bool)}
+}
+
+func (p *FakeProvider) Send(msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.invalid[msg.Token] {
+		return ErrInvalidToken
+	}
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("push provider unavailable")
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+// Invalidate makes every later send to the token fail with ErrInvalidToken
+func (p *FakeProvider) Invalidate(token string) {
+	p.mu.Lock()
+	p.invalid[token] = true
+	p.mu.Unlock()
+}
+
+// FailNext makes the next n sends fail with a retryable error
+func (p *FakeProvider) FailNext(n int) {
+	p.mu.Lock()
+	p.failures = n
+	p.mu.Unlock()
+}
+
+// Sent returns the messages delivered so far
+func (p *FakeProvider) Sent() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.sent...)
+}
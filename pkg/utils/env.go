@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// EnvDuration reads a duration (e.g. "24h", "15m") from the environment
+func EnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s '%s', using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// EnvInt reads a positive integer from the environment
+func EnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s '%s', using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
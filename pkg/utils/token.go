@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Unambiguous characters for codes people have to type in
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RandomCode returns a short human friendly code, e.g. for invites
+func RandomCode(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// RandomToken returns a hex encoded random token of the given number of bytes
+func RandomToken(bytes int) (string, error) {
+	buf := make([]byte, bytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken hashes a token for storage so a database leak doesn't leak usable tokens
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,40 +1,79 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 )
 
-func SaveUploadedFile(file *multipart.FileHeader, destPath string) (string, error) {
+var (
+	ErrNotAnImage   = errors.New("file must be a JPEG, PNG or WebP image")
+	ErrFileTooLarge = errors.New("file is too large")
+)
+
+// imageExtensions are the image types accepted for upload, by sniffed content type
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// SaveUploadedImage stores an uploaded JPEG, PNG or WebP image of at most
+// maxSize bytes under a random name. The type is sniffed from the content and
+// decides the extension; the client's file name and Content-Type are ignored,
+// since the file is served back from our origin.
+func SaveUploadedImage(file *multipart.FileHeader, destPath string, maxSize int64) (string, error) {
+	if file.Size > maxSize {
+		return "", ErrFileTooLarge
+	}
 	src, err := file.Open()
 	if err != nil {
 		return "", err
 	}
 	defer src.Close()
 
-	// Ensure directory exists
-	// Using .gemini scratch path implicitly or relative to execution?
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", ErrNotAnImage
+	}
+	ext, ok := imageExtensions[http.DetectContentType(head[:n])]
+	if !ok {
+		return "", ErrNotAnImage
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
 	// We'll use relative "uploads" folder
 	if err := os.MkdirAll(destPath, os.ModePerm); err != nil {
 		return "", err
 	}
 
-	filename := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-	filepath := filepath.Join(destPath, filename)
+	name, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(destPath, name+ext)
 
-	dst, err := os.Create(filepath)
+	dst, err := os.Create(path)
 	if err != nil {
 		return "", err
 	}
 	defer dst.Close()
 
-	if _, err = io.Copy(dst, src); err != nil {
+	// The header's size is the client's word; stop at the limit either way
+	written, err := io.Copy(dst, io.LimitReader(src, maxSize+1))
+	if err == nil && written > maxSize {
+		err = ErrFileTooLarge
+	}
+	if err != nil {
+		dst.Close()
+		os.Remove(path)
 		return "", err
 	}
-
-	return filepath, nil
+	return path, nil
 }
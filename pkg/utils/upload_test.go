@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"path/filepath"
+	"testing"
+)
+
+// formFile builds the header of a file uploaded with the given name and content
+func formFile(t *testing.T, name, contentType string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="image"; filename="` + name + `"`},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["image"][0]
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestSaveUploadedImage(t *testing.T) {
+	dir := t.TempDir()
+	path, err := SaveUploadedImage(formFile(t, "../../photo.html", "text/html", pngHeader), dir, 1024)
+	if err != nil {
+		t.Fatalf("SaveUploadedImage: %v", err)
+	}
+	if filepath.Dir(path) != dir || filepath.Ext(path) != ".png" {
+		t.Errorf("saved as %s, want a .png in %s", path, dir)
+	}
+}
+
+func TestSaveUploadedImageRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		want    error
+	}{
+		{name: "html sent as png", file: "photo.png", content: []byte("<html><script>alert(1)</script></html>"), want: ErrNotAnImage},
+		{name: "svg", file: "photo.svg", content: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), want: ErrNotAnImage},
+		{name: "gif", file: "photo.gif", content: []byte("GIF89a\x01\x00\x01\x00"), want: ErrNotAnImage},
+		{name: "too large", file: "photo.png", content: append(pngHeader, make([]byte, 2048)...), want: ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SaveUploadedImage(formFile(t, tt.file, "image/png", tt.content), t.TempDir(), 1024)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}